@@ -0,0 +1,270 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ScribeConfigSpec mirrors the controller configuration file.
+type ScribeConfigSpec struct {
+	// Types lists the observed types.
+	// +optional
+	Types []Type `json:"types,omitempty"`
+	// Templates contains named templates shared by all namespaces.
+	// +optional
+	Templates map[string]string `json:"templates,omitempty"`
+	// Limits bounds the resources used by template execution.
+	// +optional
+	Limits Limits `json:"limits,omitempty"`
+	// Include selects additional types through the discovery API.
+	// +optional
+	Include []TypeSelector `json:"include,omitempty"`
+	// Exclude removes types selected by Include.
+	// +optional
+	Exclude []TypeSelector `json:"exclude,omitempty"`
+	// DiscoveryInterval is how often the types selected by Include are resolved again.
+	// +optional
+	DiscoveryInterval *metav1.Duration `json:"discoveryInterval,omitempty"`
+	// Policy restricts the keys propagated from namespaces to objects.
+	// +optional
+	Policy Policy `json:"policy,omitempty"`
+	// Filter selects the objects managed for all types.
+	Filter `json:",inline"`
+}
+
+// Type is a single observed type, given either by apiVersion, or by group only.
+type Type struct {
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+	// Group selects the preferred version served by the cluster.
+	// +optional
+	Group string `json:"group,omitempty"`
+	// +kubebuilder:validation:MinLength=1
+	Kind string `json:"kind"`
+	// Fields restricts the object fields that templates can read.
+	// +optional
+	Fields Fields `json:"fields,omitempty"`
+	// Filter selects the objects managed for this type, in addition to the global filter.
+	Filter `json:",inline"`
+	// Controller tunes the controller reconciling the type.
+	// +optional
+	Controller ControllerOptions `json:"controller,omitempty"`
+}
+
+// Fields restricts the object fields passed to templates.
+type Fields struct {
+	// Allow lists the only fields that templates can read.
+	// +optional
+	Allow []string `json:"allow,omitempty"`
+	// Deny lists the fields that templates cannot read. If not set, the default list is used,
+	// and setting it to an empty list disables the defaults.
+	// +optional
+	Deny []string `json:"deny"`
+}
+
+// Filter selects the managed objects.
+type Filter struct {
+	// ObjectSelector selects objects by their labels.
+	// +optional
+	ObjectSelector *metav1.LabelSelector `json:"objectSelector,omitempty"`
+	// NamespaceSelector selects objects by the labels of their namespace.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// Namespaces lists the only namespaces whose objects are managed. Names can be glob patterns.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+	// ExcludedNamespaces lists the namespaces whose objects are never managed. Names can be glob patterns.
+	// +optional
+	ExcludedNamespaces []string `json:"excludedNamespaces,omitempty"`
+}
+
+// Policy restricts the keys propagated from namespaces to objects.
+type Policy struct {
+	KeyPolicy `json:",inline"`
+	// Namespaced lists key policies applied to the namespaces selected by their labels.
+	// +optional
+	Namespaced []NamespacedKeyPolicy `json:"namespaced,omitempty"`
+	// Restricted lists the keys that only members of some groups can add to namespace blocks.
+	// +optional
+	Restricted []RestrictedKeys `json:"restricted,omitempty"`
+	// Values lists constraints on the values of the keys.
+	// +optional
+	Values []ValueRule `json:"values,omitempty"`
+}
+
+// ValueRule constrains the values of the keys. All constraints that are set must be satisfied.
+type ValueRule struct {
+	// Keys lists the constrained keys, as glob patterns.
+	// +kubebuilder:validation:MinItems=1
+	Keys []string `json:"keys"`
+	// Pattern is a regular expression the values must match.
+	// +optional
+	Pattern string `json:"pattern,omitempty"`
+	// Enum lists the only accepted values.
+	// +optional
+	Enum []string `json:"enum,omitempty"`
+	// MaxLength is the maximum length of the values, in characters.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxLength int32 `json:"maxLength,omitempty"`
+}
+
+// RestrictedKeys restricts who can add the keys to namespace blocks, or change their values.
+type RestrictedKeys struct {
+	// Keys lists the restricted keys, as glob patterns.
+	// +kubebuilder:validation:MinItems=1
+	Keys []string `json:"keys"`
+	// Groups lists the groups whose members can add the keys.
+	// +kubebuilder:validation:MinItems=1
+	Groups []string `json:"groups"`
+}
+
+// NamespacedKeyPolicy is a key policy applied to the namespaces selected by their labels.
+type NamespacedKeyPolicy struct {
+	// NamespaceSelector selects the namespaces. If not set, all namespaces are selected.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	KeyPolicy         `json:",inline"`
+}
+
+// KeyPolicy allows and denies keys by glob patterns.
+type KeyPolicy struct {
+	// AllowedKeys lists the only keys that can be propagated. If empty, all keys are allowed.
+	// +optional
+	AllowedKeys []string `json:"allowedKeys,omitempty"`
+	// DeniedKeys lists the keys that are never propagated. It takes precedence over AllowedKeys.
+	// +optional
+	DeniedKeys []string `json:"deniedKeys,omitempty"`
+}
+
+// ControllerOptions tunes a controller.
+type ControllerOptions struct {
+	// MaxConcurrentReconciles is the number of objects reconciled in parallel.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxConcurrentReconciles int32 `json:"maxConcurrentReconciles,omitempty"`
+	// RateLimiter limits how often objects are queued.
+	// +optional
+	RateLimiter RateLimiter `json:"rateLimiter,omitempty"`
+}
+
+// RateLimiter limits how often objects are queued.
+type RateLimiter struct {
+	// +optional
+	BaseDelay *metav1.Duration `json:"baseDelay,omitempty"`
+	// +optional
+	MaxDelay *metav1.Duration `json:"maxDelay,omitempty"`
+	// QPS may be fractional, e.g. 0.5 queues an object every two seconds.
+	// +kubebuilder:validation:Type=number
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	QPS float64 `json:"qps,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Burst int32 `json:"burst,omitempty"`
+}
+
+// Limits bounds the resources used by template execution.
+type Limits struct {
+	// +optional
+	ExecutionTimeout *metav1.Duration `json:"executionTimeout,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxOutputSize int32 `json:"maxOutputSize,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxTemplateLength int32 `json:"maxTemplateLength,omitempty"`
+}
+
+// TypeSelector selects types through the discovery API.
+type TypeSelector struct {
+	// +optional
+	Group string `json:"group,omitempty"`
+	// +optional
+	Kind string `json:"kind,omitempty"`
+}
+
+// TypeState is the state of a configured type.
+// +kubebuilder:validation:Enum=Active;Pending;Failed
+type TypeState string
+
+const (
+	// TypeActive means that the controller for the type is running.
+	TypeActive TypeState = "Active"
+	// TypePending means that the type is not served by the cluster yet.
+	TypePending TypeState = "Pending"
+	// TypeFailed means that the controller for the type could not be started.
+	TypeFailed TypeState = "Failed"
+)
+
+// TypeStatus is the status of a single configured type.
+type TypeStatus struct {
+	// +optional
+	Group string `json:"group,omitempty"`
+	// +optional
+	Version string    `json:"version,omitempty"`
+	Kind    string    `json:"kind"`
+	State   TypeState `json:"state"`
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ScribeConfigStatus defines the observed state of ScribeConfig.
+type ScribeConfigStatus struct {
+	// ObservedGeneration is the generation of the spec applied by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the state of the configuration. The Ready condition
+	// reports whether the spec is valid and was applied.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Types reports the state of every configured type.
+	// +optional
+	Types []TypeStatus `json:"types,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ScribeConfig is the controller configuration, used instead of the configuration file
+// when the controller is started with --config-source=crd.
+type ScribeConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ScribeConfigSpec   `json:"spec,omitempty"`
+	Status ScribeConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ScribeConfigList contains a list of ScribeConfig.
+type ScribeConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ScribeConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ScribeConfig{}, &ScribeConfigList{})
+}
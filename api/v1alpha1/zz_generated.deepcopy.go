@@ -0,0 +1,461 @@
+//go:build !ignore_autogenerated
+
+/*
+Copyright 2024 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllerOptions) DeepCopyInto(out *ControllerOptions) {
+	*out = *in
+	in.RateLimiter.DeepCopyInto(&out.RateLimiter)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerOptions.
+func (in *ControllerOptions) DeepCopy() *ControllerOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllerOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Fields) DeepCopyInto(out *Fields) {
+	*out = *in
+	if in.Allow != nil {
+		in, out := &in.Allow, &out.Allow
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Deny != nil {
+		in, out := &in.Deny, &out.Deny
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Fields.
+func (in *Fields) DeepCopy() *Fields {
+	if in == nil {
+		return nil
+	}
+	out := new(Fields)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Filter) DeepCopyInto(out *Filter) {
+	*out = *in
+	if in.ObjectSelector != nil {
+		in, out := &in.ObjectSelector, &out.ObjectSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludedNamespaces != nil {
+		in, out := &in.ExcludedNamespaces, &out.ExcludedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Filter.
+func (in *Filter) DeepCopy() *Filter {
+	if in == nil {
+		return nil
+	}
+	out := new(Filter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyPolicy) DeepCopyInto(out *KeyPolicy) {
+	*out = *in
+	if in.AllowedKeys != nil {
+		in, out := &in.AllowedKeys, &out.AllowedKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeniedKeys != nil {
+		in, out := &in.DeniedKeys, &out.DeniedKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyPolicy.
+func (in *KeyPolicy) DeepCopy() *KeyPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Limits) DeepCopyInto(out *Limits) {
+	*out = *in
+	if in.ExecutionTimeout != nil {
+		in, out := &in.ExecutionTimeout, &out.ExecutionTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Limits.
+func (in *Limits) DeepCopy() *Limits {
+	if in == nil {
+		return nil
+	}
+	out := new(Limits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespacedKeyPolicy) DeepCopyInto(out *NamespacedKeyPolicy) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.KeyPolicy.DeepCopyInto(&out.KeyPolicy)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacedKeyPolicy.
+func (in *NamespacedKeyPolicy) DeepCopy() *NamespacedKeyPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespacedKeyPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Policy) DeepCopyInto(out *Policy) {
+	*out = *in
+	in.KeyPolicy.DeepCopyInto(&out.KeyPolicy)
+	if in.Namespaced != nil {
+		in, out := &in.Namespaced, &out.Namespaced
+		*out = make([]NamespacedKeyPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Restricted != nil {
+		in, out := &in.Restricted, &out.Restricted
+		*out = make([]RestrictedKeys, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]ValueRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Policy.
+func (in *Policy) DeepCopy() *Policy {
+	if in == nil {
+		return nil
+	}
+	out := new(Policy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RateLimiter) DeepCopyInto(out *RateLimiter) {
+	*out = *in
+	if in.BaseDelay != nil {
+		in, out := &in.BaseDelay, &out.BaseDelay
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxDelay != nil {
+		in, out := &in.MaxDelay, &out.MaxDelay
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimiter.
+func (in *RateLimiter) DeepCopy() *RateLimiter {
+	if in == nil {
+		return nil
+	}
+	out := new(RateLimiter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestrictedKeys) DeepCopyInto(out *RestrictedKeys) {
+	*out = *in
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestrictedKeys.
+func (in *RestrictedKeys) DeepCopy() *RestrictedKeys {
+	if in == nil {
+		return nil
+	}
+	out := new(RestrictedKeys)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScribeConfig) DeepCopyInto(out *ScribeConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScribeConfig.
+func (in *ScribeConfig) DeepCopy() *ScribeConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ScribeConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ScribeConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScribeConfigList) DeepCopyInto(out *ScribeConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ScribeConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScribeConfigList.
+func (in *ScribeConfigList) DeepCopy() *ScribeConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(ScribeConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ScribeConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScribeConfigSpec) DeepCopyInto(out *ScribeConfigSpec) {
+	*out = *in
+	if in.Types != nil {
+		in, out := &in.Types, &out.Types
+		*out = make([]Type, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Limits.DeepCopyInto(&out.Limits)
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]TypeSelector, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]TypeSelector, len(*in))
+		copy(*out, *in)
+	}
+	if in.DiscoveryInterval != nil {
+		in, out := &in.DiscoveryInterval, &out.DiscoveryInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	in.Policy.DeepCopyInto(&out.Policy)
+	in.Filter.DeepCopyInto(&out.Filter)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScribeConfigSpec.
+func (in *ScribeConfigSpec) DeepCopy() *ScribeConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScribeConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScribeConfigStatus) DeepCopyInto(out *ScribeConfigStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Types != nil {
+		in, out := &in.Types, &out.Types
+		*out = make([]TypeStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScribeConfigStatus.
+func (in *ScribeConfigStatus) DeepCopy() *ScribeConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ScribeConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Type) DeepCopyInto(out *Type) {
+	*out = *in
+	in.Fields.DeepCopyInto(&out.Fields)
+	in.Filter.DeepCopyInto(&out.Filter)
+	in.Controller.DeepCopyInto(&out.Controller)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Type.
+func (in *Type) DeepCopy() *Type {
+	if in == nil {
+		return nil
+	}
+	out := new(Type)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TypeSelector) DeepCopyInto(out *TypeSelector) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TypeSelector.
+func (in *TypeSelector) DeepCopy() *TypeSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(TypeSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TypeStatus) DeepCopyInto(out *TypeStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TypeStatus.
+func (in *TypeStatus) DeepCopy() *TypeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TypeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ValueRule) DeepCopyInto(out *ValueRule) {
+	*out = *in
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Enum != nil {
+		in, out := &in.Enum, &out.Enum
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValueRule.
+func (in *ValueRule) DeepCopy() *ValueRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ValueRule)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -20,20 +20,28 @@ import (
 	"crypto/tls"
 	"flag"
 	"os"
+	"strings"
 
+	scribev1alpha1 "github.com/anza-labs/scribe/api/v1alpha1"
 	"github.com/anza-labs/scribe/internal/config"
 	"github.com/anza-labs/scribe/internal/controller"
+	webhookv1 "github.com/anza-labs/scribe/internal/webhook/v1"
+	webhookscribev1alpha1 "github.com/anza-labs/scribe/internal/webhook/v1alpha1"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
-	yaml "sigs.k8s.io/yaml/goyaml.v3"
 
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 )
@@ -45,11 +53,19 @@ var (
 
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(scribev1alpha1.AddToScheme(scheme))
 
 	// +kubebuilder:scaffold:scheme
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate-config" {
+		os.Exit(validateConfig(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "rbac" {
+		os.Exit(generateRBAC(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
@@ -57,6 +73,19 @@ func main() {
 	var enableHTTP2 bool
 	var tlsOpts []func(*tls.Config)
 	var configPath string
+	var watchConfig bool
+	var configSource string
+	var configName string
+	var kubeAPIQPS float64
+	var kubeAPIBurst int
+	var watchNamespaces string
+	var annotationDomain string
+	var class string
+	var requireOptIn bool
+	var enableWebhooks bool
+	var mutateOnCreate bool
+	var protectAnnotations string
+	var serviceAccountUsername string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -69,6 +98,35 @@ func main() {
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
 	flag.StringVar(&configPath, "config-path", "config.yaml", "Path to the configuration file containing apiVersion"+
 		"and kind definitions for observed resources.")
+	flag.BoolVar(&watchConfig, "watch-config", true,
+		"If set, the configuration file is reloaded when it changes, without restarting the manager.")
+	flag.StringVar(&configSource, "config-source", "file",
+		"Source of the configuration: 'file' reads --config-path, 'crd' reads the ScribeConfig named by --config-name.")
+	flag.StringVar(&configName, "config-name", "default",
+		"Name of the ScribeConfig used when --config-source=crd.")
+	flag.Float64Var(&kubeAPIQPS, "kube-api-qps", 20,
+		"Maximum number of queries per second sent to the Kubernetes API server.")
+	flag.IntVar(&kubeAPIBurst, "kube-api-burst", 30,
+		"Maximum burst of queries sent to the Kubernetes API server.")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", "",
+		"Comma-separated list of namespaces to watch. If set, only namespaced Roles are required.")
+	flag.StringVar(&annotationDomain, "annotation-domain", controller.DefaultDomain,
+		"Domain of the annotations read from namespaces and written to objects.")
+	flag.StringVar(&class, "class", "",
+		"Class of this instance. If set, only namespace blocks addressed to the class are processed, "+
+			"e.g. <class>.<annotation-domain>/annotations.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"If set, the admission webhooks validating Namespaces and ScribeConfigs are served.")
+	flag.BoolVar(&mutateOnCreate, "mutate-on-create", false,
+		"If set with --enable-webhooks, the mutating admission webhook applying annotations to new objects is served.")
+	flag.StringVar(&protectAnnotations, "protect-annotations", "",
+		"If set with --enable-webhooks, changes to managed annotations by anyone but scribe are rejected ('deny'), "+
+			"or accepted with a warning ('warn').")
+	flag.StringVar(&serviceAccountUsername, "service-account-username",
+		"system:serviceaccount:scribe-system:scribe-controller-manager",
+		"Username scribe authenticates as, whose changes to managed annotations are always accepted.")
+	flag.BoolVar(&requireOptIn, "require-opt-in", false,
+		"If set, only the blocks of namespaces labeled with <annotation-domain>/enabled=true are honored.")
 	klog.InitFlags(nil)
 	flag.Parse()
 
@@ -117,45 +175,152 @@ func main() {
 		metricsServerOptions.FilterProvider = filters.WithAuthenticationAndAuthorization
 	}
 
-	f, err := os.Open(configPath)
-	if err != nil {
-		setupLog.Error(err, "Unable to open the config file")
+	cfg := &config.Config{}
+	switch configSource {
+	case "file":
+		var err error
+		cfg, err = config.Load(configPath)
+		if err != nil {
+			setupLog.Error(err, "Unable to load the config file")
+			os.Exit(1)
+		}
+	case "crd":
+		// The configuration is applied once the ScribeConfig is reconciled, and loaded for the webhooks.
+	default:
+		setupLog.Error(nil, "Unknown config source", "config_source", configSource)
 		os.Exit(1)
 	}
-	defer f.Close() //nolint:errcheck // best effort close
 
-	cfg := &config.Config{}
-
-	err = yaml.NewDecoder(f).Decode(cfg)
+	keys, err := controller.NewAnnotationKeys(annotationDomain, class)
 	if err != nil {
-		setupLog.Error(err, "Unable to decode the config file")
+		setupLog.Error(err, "Invalid annotation keys")
 		os.Exit(1)
 	}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	leaderElectionID := "7d117632.anza-labs.dev"
+	if class != "" {
+		// Instances of different classes run independently of each other.
+		leaderElectionID = class + "." + leaderElectionID
+	}
+
+	restConfig := ctrl.GetConfigOrDie()
+	restConfig.QPS = float32(kubeAPIQPS)
+	restConfig.Burst = kubeAPIBurst
+
+	options := ctrl.Options{
 		Scheme:                 scheme,
 		Metrics:                metricsServerOptions,
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "7d117632.anza-labs.dev",
-	})
+		LeaderElectionID:       leaderElectionID,
+	}
+
+	controllerSetOpts := []controller.ControllerSetOption{controller.WithAnnotationKeys(keys)}
+	if requireOptIn {
+		controllerSetOpts = append(controllerSetOpts, controller.WithRequiredOptIn())
+	}
+	// Events about the types, e.g. missing permissions, are emitted on the Pod of the manager, if known.
+	if podName, podNamespace := os.Getenv("POD_NAME"), os.Getenv("POD_NAMESPACE"); podName != "" && podNamespace != "" {
+		controllerSetOpts = append(controllerSetOpts, controller.WithEventObject(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: podName, Namespace: podNamespace, UID: types.UID(os.Getenv("POD_UID"))},
+		}))
+	}
+	namespaces := splitNamespaces(watchNamespaces)
+	if len(namespaces) > 0 {
+		if configSource == "crd" {
+			setupLog.Error(nil, "The ScribeConfig is cluster-scoped and cannot be used with --watch-namespaces")
+			os.Exit(1)
+		}
+
+		options.Cache.DefaultNamespaces = make(map[string]cache.Config, len(namespaces))
+		for _, ns := range namespaces {
+			options.Cache.DefaultNamespaces[ns] = cache.Config{}
+		}
+		// Namespaces are cluster-scoped, caching them would require list and watch on all of them.
+		options.Client.Cache = &client.CacheOptions{DisableFor: []client.Object{&corev1.Namespace{}}}
+
+		setupLog.Info("Restricting the manager to namespaces", "namespaces", namespaces)
+		controllerSetOpts = append(controllerSetOpts, controller.WithWatchNamespaces(namespaces))
+	}
+
+	mgr, err := ctrl.NewManager(restConfig, options)
 	if err != nil {
 		setupLog.Error(err, "Unable to start manager")
 		os.Exit(1)
 	}
 
-	for _, t := range cfg.Types {
-		t := t
+	controllers := controller.NewControllerSet(mgr, cfg, controllerSetOpts...)
+	if err := mgr.Add(controllers); err != nil {
+		setupLog.Error(err, "Unable to set up controllers")
+		os.Exit(1)
+	}
+
+	// The webhooks are served by every replica, while the configuration is applied by the leader.
+	webhookConfig := controllers.Config
+	if configSource == "crd" {
+		loader := &controller.ScribeConfigLoader{
+			Cache: mgr.GetCache(),
+			Name:  configName,
+		}
+		if err := mgr.Add(loader); err != nil {
+			setupLog.Error(err, "Unable to set up config loader")
+			os.Exit(1)
+		}
+		if err := mgr.AddReadyzCheck("config", loader.ReadyzCheck); err != nil {
+			setupLog.Error(err, "Unable to set up config check")
+			os.Exit(1)
+		}
+		webhookConfig = loader.Config
 
-		if err = (&controller.UnstructuredReconciler{
-			Client:   mgr.GetClient(),
-			Scheme:   mgr.GetScheme(),
-			Recorder: mgr.GetEventRecorderFor(t.GroupVersionKind().String()),
-		}).SetupWithManager(mgr, t.GroupVersionKind()); err != nil {
-			setupLog.Error(err, "unable to create controller", "controller", "Unstructured")
+		if err := (&controller.ScribeConfigReconciler{
+			Client:      mgr.GetClient(),
+			Name:        configName,
+			Controllers: controllers,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "Unable to create controller", "controller", "ScribeConfig")
+			os.Exit(1)
+		}
+	} else if watchConfig {
+		if err := mgr.Add(config.NewWatcher(configPath, controllers.Update)); err != nil {
+			setupLog.Error(err, "Unable to set up config watcher")
+			os.Exit(1)
+		}
+	}
+	if enableWebhooks {
+		if err := webhookv1.SetupNamespaceWebhookWithManager(mgr, &webhookv1.NamespaceCustomValidator{
+			Keys:   keys,
+			Config: webhookConfig,
+		}); err != nil {
+			setupLog.Error(err, "Unable to create webhook", "webhook", "Namespace")
 			os.Exit(1)
 		}
+		if err := webhookscribev1alpha1.SetupScribeConfigWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "Unable to create webhook", "webhook", "ScribeConfig")
+			os.Exit(1)
+		}
+		if mutateOnCreate {
+			if err := webhookv1.SetupObjectWebhookWithManager(mgr, &webhookv1.ObjectMutator{
+				Client:          mgr.GetClient(),
+				Keys:            keys,
+				Config:          webhookConfig,
+				RequireOptIn:    requireOptIn,
+				WatchNamespaces: namespaces,
+			}); err != nil {
+				setupLog.Error(err, "Unable to create webhook", "webhook", "Object")
+				os.Exit(1)
+			}
+		}
+		if protectAnnotations != "" {
+			if err := webhookv1.SetupOwnershipWebhookWithManager(mgr, &webhookv1.OwnershipValidator{
+				Keys:     keys,
+				Username: serviceAccountUsername,
+				Mode:     webhookv1.ProtectionMode(protectAnnotations),
+			}); err != nil {
+				setupLog.Error(err, "Unable to create webhook", "webhook", "Ownership")
+				os.Exit(1)
+			}
+		}
 	}
 	// +kubebuilder:scaffold:builder
 
@@ -167,6 +332,14 @@ func main() {
 		setupLog.Error(err, "Unable to set up ready check")
 		os.Exit(1)
 	}
+	if err := mgr.AddReadyzCheck("types", controllers.ReadyzCheck); err != nil {
+		setupLog.Error(err, "Unable to set up types check")
+		os.Exit(1)
+	}
+	if err := mgr.AddReadyzCheck("permissions", controllers.PermissionsCheck); err != nil {
+		setupLog.Error(err, "Unable to set up permissions check")
+		os.Exit(1)
+	}
 
 	setupLog.Info("Starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
@@ -174,3 +347,14 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// splitNamespaces splits a comma-separated list of namespaces, dropping empty entries.
+func splitNamespaces(s string) []string {
+	var namespaces []string
+	for _, ns := range strings.Split(s, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
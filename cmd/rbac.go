@@ -0,0 +1,211 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/anza-labs/scribe/internal/config"
+	"github.com/anza-labs/scribe/internal/controller"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/restmapper"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// rbacOptions are the options of the rbac subcommand.
+type rbacOptions struct {
+	offline                 bool
+	name                    string
+	namespaces              string
+	serviceAccount          string
+	serviceAccountNamespace string
+}
+
+// generateRBAC implements the rbac subcommand. It prints a ClusterRole granting access to the
+// configured types, bound to the service account of the manager, or a Role and RoleBinding in
+// every namespace given with --namespaces. Kinds are resolved to resources through discovery if
+// a cluster is reachable, or through the built-in kinds otherwise. It returns the exit code.
+func generateRBAC(args []string, stdout, stderr io.Writer) int {
+	opts := rbacOptions{}
+
+	fs := flag.NewFlagSet("rbac", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.BoolVar(&opts.offline, "offline", false,
+		"If set, the kinds are resolved through the built-in kinds, instead of the cluster.")
+	fs.StringVar(&opts.name, "name", "scribe-types-role", "Name of the generated roles and bindings.")
+	fs.StringVar(&opts.namespaces, "namespaces", "",
+		"Comma-separated list of namespaces. If set, a Role and RoleBinding is generated in every namespace, "+
+			"like required by --watch-namespaces.")
+	fs.StringVar(&opts.serviceAccount, "service-account", "scribe-controller-manager",
+		"Name of the service account of the manager.")
+	fs.StringVar(&opts.serviceAccountNamespace, "service-account-namespace", "scribe-system",
+		"Namespace of the service account of the manager.")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: rbac [--offline] [--namespaces <namespaces>] <file>")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	path := fs.Arg(0)
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		for _, fe := range config.FieldErrors(err) {
+			fmt.Fprintf(stderr, "%s: %v\n", path, fe)
+		}
+		return 1
+	}
+
+	var dc discovery.DiscoveryInterface
+	var mapper meta.RESTMapper
+	if !opts.offline {
+		dc = discoveryClient(stderr)
+		if dc != nil {
+			mapper = restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(dc))
+		}
+	}
+
+	types, err := controller.ConfiguredTypes(cfg, dc)
+	if err != nil {
+		fmt.Fprintf(stderr, "Unable to resolve the included types: %v\n", err)
+		return 1
+	}
+
+	rules, guessed, err := controller.PolicyRules(types, mapper)
+	if err != nil {
+		fmt.Fprintf(stderr, "Unable to resolve the types: %v\n", err)
+		return 1
+	}
+
+	for _, gk := range guessed {
+		fmt.Fprintf(stderr, "The resource of %s is guessed from its kind, check it before applying the role\n", gk)
+	}
+
+	objects := rbacObjects(opts, rules)
+	for _, obj := range objects {
+		if err := writeObject(stdout, obj); err != nil {
+			fmt.Fprintf(stderr, "Unable to write the roles: %v\n", err)
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// discoveryClient returns a client for the discovery API of the cluster, or nil if no cluster is reachable.
+func discoveryClient(stderr io.Writer) discovery.DiscoveryInterface {
+	restConfig, err := ctrl.GetConfig()
+	if err != nil {
+		fmt.Fprintf(stderr, "Resolving kinds offline, no cluster configured: %v\n", err)
+		return nil
+	}
+	restConfig.Timeout = 10 * time.Second
+
+	dc, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		fmt.Fprintf(stderr, "Resolving kinds offline, unable to create discovery client: %v\n", err)
+		return nil
+	}
+
+	if _, err := dc.ServerVersion(); err != nil {
+		fmt.Fprintf(stderr, "Resolving kinds offline, cluster is not reachable: %v\n", err)
+		return nil
+	}
+
+	return dc
+}
+
+// rbacObjects returns a ClusterRole and its binding, or a Role and RoleBinding in every namespace.
+func rbacObjects(opts rbacOptions, rules []rbacv1.PolicyRule) []runtime.Object {
+	subjects := []rbacv1.Subject{{
+		Kind:      rbacv1.ServiceAccountKind,
+		Name:      opts.serviceAccount,
+		Namespace: opts.serviceAccountNamespace,
+	}}
+	labels := map[string]string{"app.kubernetes.io/name": "scribe"}
+
+	namespaces := splitNamespaces(opts.namespaces)
+	if len(namespaces) == 0 {
+		return []runtime.Object{
+			&rbacv1.ClusterRole{
+				TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+				ObjectMeta: metav1.ObjectMeta{Name: opts.name, Labels: labels},
+				Rules:      rules,
+			},
+			&rbacv1.ClusterRoleBinding{
+				TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"},
+				ObjectMeta: metav1.ObjectMeta{Name: opts.name, Labels: labels},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: opts.name},
+				Subjects:   subjects,
+			},
+		}
+	}
+
+	var objects []runtime.Object
+	for _, ns := range namespaces {
+		objects = append(objects,
+			&rbacv1.Role{
+				TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "Role"},
+				ObjectMeta: metav1.ObjectMeta{Name: opts.name, Namespace: ns, Labels: labels},
+				Rules:      rules,
+			},
+			&rbacv1.RoleBinding{
+				TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleBinding"},
+				ObjectMeta: metav1.ObjectMeta{Name: opts.name, Namespace: ns, Labels: labels},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: opts.name},
+				Subjects:   subjects,
+			},
+		)
+	}
+
+	return objects
+}
+
+// writeObject writes the object as a YAML document, without the empty creation timestamp.
+func writeObject(w io.Writer, obj runtime.Object) error {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	unstructured.RemoveNestedField(u, "metadata", "creationTimestamp")
+
+	raw, err := yaml.Marshal(u)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "---\n%s", raw)
+	return err
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/anza-labs/scribe/internal/config"
+
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/restmapper"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// validateConfig implements the validate-config subcommand. It validates the configuration file,
+// and prints every problem with its YAML path. If a cluster is reachable, the configured kinds
+// must be served by it. It returns the exit code.
+func validateConfig(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("validate-config", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	offline := fs.Bool("offline", false, "If set, the kinds are not resolved against the cluster.")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: validate-config [--offline] <file>")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	path := fs.Arg(0)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", path, err)
+		return 1
+	}
+
+	cfg, err := config.Decode(data)
+	if err == nil {
+		err = cfg.Validate()
+	}
+
+	if err == nil && !*offline {
+		err = validateKinds(cfg, stderr)
+	}
+
+	if err != nil {
+		for _, fe := range config.FieldErrors(err) {
+			fmt.Fprintf(stderr, "%s: %v\n", path, fe)
+		}
+		return 1
+	}
+
+	fmt.Fprintf(stdout, "%s: valid\n", path)
+	return 0
+}
+
+// validateKinds checks the configured kinds against the cluster. If no cluster is reachable,
+// the check is skipped.
+func validateKinds(cfg *config.Config, stderr io.Writer) error {
+	restConfig, err := ctrl.GetConfig()
+	if err != nil {
+		fmt.Fprintf(stderr, "Skipping kind resolution, no cluster configured: %v\n", err)
+		return nil
+	}
+	restConfig.Timeout = 10 * time.Second
+
+	dc, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("unable to create discovery client: %w", err)
+	}
+
+	if _, err := dc.ServerVersion(); err != nil {
+		fmt.Fprintf(stderr, "Skipping kind resolution, cluster is not reachable: %v\n", err)
+		return nil
+	}
+
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(dc))
+
+	return cfg.ValidateKinds(mapper)
+}
@@ -1,16 +1,517 @@
 package config
 
-import "k8s.io/apimachinery/pkg/runtime/schema"
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	yaml "sigs.k8s.io/yaml/goyaml.v3"
+)
 
 type Config struct {
 	Types []Type `json:"types"`
+	// Templates contains named templates shared by all namespaces. Namespace
+	// annotation blocks can call them with {{ template "name" . }}.
+	Templates map[string]string `json:"templates,omitempty" yaml:"templates,omitempty"`
+	// Limits bounds the resources used by template execution.
+	Limits Limits `json:"limits,omitempty" yaml:"limits,omitempty"`
+	// Include selects additional types through the discovery API.
+	Include []TypeSelector `json:"include,omitempty" yaml:"include,omitempty"`
+	// Exclude removes types selected by Include.
+	Exclude []TypeSelector `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+	// DiscoveryInterval is how often the types selected by Include are resolved again.
+	DiscoveryInterval time.Duration `json:"discoveryInterval,omitempty" yaml:"discoveryInterval,omitempty"`
+	// Policy restricts the keys propagated from namespaces to objects.
+	Policy Policy `json:"policy,omitempty" yaml:"policy,omitempty"`
+	// Filter selects the objects managed for all types.
+	Filter `json:",inline" yaml:",inline"`
+}
+
+const DefaultDiscoveryInterval = 5 * time.Minute
+
+var errMustNotBeNegative = errors.New("must not be negative")
+
+// GetDiscoveryInterval returns the discovery interval, or the default if it is not set.
+func (c *Config) GetDiscoveryInterval() time.Duration {
+	if c.DiscoveryInterval == 0 {
+		return DefaultDiscoveryInterval
+	}
+
+	return c.DiscoveryInterval
+}
+
+// Load reads, decodes and validates the configuration file.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the config file: %w", err)
+	}
+
+	return Parse(data)
+}
+
+// Parse decodes and validates the configuration, and compiles the patterns of the value rules.
+func Parse(data []byte) (*Config, error) {
+	cfg, err := Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode the config file: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file: %w", err)
+	}
+
+	cfg.Policy.compile()
+
+	return cfg, nil
+}
+
+// Decode decodes the configuration strictly: unknown and duplicate fields are rejected.
+// The errors are reported as field errors, where the path of the field can be found.
+func Decode(data []byte) (*Config, error) {
+	cfg := &Config{}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, decodeErrors(data, err)
+	}
+
+	return cfg, nil
+}
+
+// Validate checks the configuration and returns all problems found, as field errors.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if _, err := c.SharedTemplates(); err != nil {
+		errs = append(errs, err)
+	}
+
+	errs = append(errs, withPath("limits", c.Limits.Validate()))
+	errs = append(errs, c.Filter.Validate())
+	errs = append(errs, withPath("policy", c.Policy.Validate()))
+
+	seen := make(map[schema.GroupKind]int, len(c.Types))
+	for i, t := range c.Types {
+		path := fmt.Sprintf("types[%d]", i)
+		errs = append(errs, withPath(path, t.Validate()))
+
+		gk := t.GroupVersionKind().GroupKind()
+		if j, ok := seen[gk]; ok {
+			errs = append(errs, &FieldError{Path: path, Err: fmt.Errorf("duplicate of types[%d]", j)})
+			continue
+		}
+		seen[gk] = i
+	}
+
+	for i, s := range c.Include {
+		errs = append(errs, withPath(fmt.Sprintf("include[%d]", i), s.Validate()))
+	}
+
+	for i, s := range c.Exclude {
+		errs = append(errs, withPath(fmt.Sprintf("exclude[%d]", i), s.Validate()))
+	}
+
+	if c.DiscoveryInterval < 0 {
+		errs = append(errs, &FieldError{Path: "discoveryInterval", Err: errMustNotBeNegative})
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateKinds checks that every configured type is served by the cluster.
+func (c *Config) ValidateKinds(mapper meta.RESTMapper) error {
+	var errs []error
+
+	for i, t := range c.Types {
+		gvk := t.GroupVersionKind()
+
+		var versions []string
+		if !t.Unversioned() {
+			versions = append(versions, gvk.Version)
+		}
+
+		if _, err := mapper.RESTMapping(gvk.GroupKind(), versions...); err != nil {
+			errs = append(errs, &FieldError{Path: fmt.Sprintf("types[%d]", i), Err: err})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// LookupType returns the type managing objects of the given kind: the configured type of the
+// same group and kind, or a type without settings if the kind is selected by the include rules.
+// Unversioned types match every version of the kind.
+func (c *Config) LookupType(gvk schema.GroupVersionKind) (Type, bool) {
+	for _, t := range c.Types {
+		tgvk := t.GroupVersionKind()
+		if tgvk.GroupKind() == gvk.GroupKind() && (t.Unversioned() || tgvk.Version == gvk.Version) {
+			return t, true
+		}
+	}
+
+	if MatchesAny(c.Include, gvk.GroupKind()) && !MatchesAny(c.Exclude, gvk.GroupKind()) {
+		apiVersion, kind := gvk.ToAPIVersionAndKind()
+		return Type{APIVersion: apiVersion, Kind: kind}, true
+	}
+
+	return Type{}, false
+}
+
+// SharedTemplates parses the named templates into a single template set.
+// The returned set is meant to be cloned before parsing namespace annotations.
+func (c *Config) SharedTemplates() (*template.Template, error) {
+	root := template.New("")
+
+	names := make([]string, 0, len(c.Templates))
+	for name := range c.Templates {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var errs []error
+	for _, name := range names {
+		path := joinPath("templates", name)
+
+		if name == "" {
+			errs = append(errs, &FieldError{Path: path, Err: errors.New("template name must not be empty")})
+			continue
+		}
+
+		if _, err := root.New(name).Parse(c.Templates[name]); err != nil {
+			errs = append(errs, &FieldError{Path: path, Err: fmt.Errorf("failed to parse template: %w", err)})
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return root, nil
+}
+
+const (
+	DefaultExecutionTimeout  = time.Second
+	DefaultMaxOutputSize     = 64 * 1024
+	DefaultMaxTemplateLength = 16 * 1024
+)
+
+// Limits bounds the resources used by template execution.
+// Zero values are replaced with defaults.
+type Limits struct {
+	// ExecutionTimeout is the maximum duration of a single template execution.
+	ExecutionTimeout time.Duration `json:"executionTimeout,omitempty" yaml:"executionTimeout,omitempty"`
+	// MaxOutputSize is the maximum size of the rendered template, in bytes.
+	MaxOutputSize int `json:"maxOutputSize,omitempty" yaml:"maxOutputSize,omitempty"`
+	// MaxTemplateLength is the maximum length of the namespace annotation block, in bytes.
+	MaxTemplateLength int `json:"maxTemplateLength,omitempty" yaml:"maxTemplateLength,omitempty"`
+}
+
+// Validate checks that none of the limits is negative.
+func (l Limits) Validate() error {
+	var errs []error
+
+	if l.ExecutionTimeout < 0 {
+		errs = append(errs, &FieldError{Path: "executionTimeout", Err: errMustNotBeNegative})
+	}
+	if l.MaxOutputSize < 0 {
+		errs = append(errs, &FieldError{Path: "maxOutputSize", Err: errMustNotBeNegative})
+	}
+	if l.MaxTemplateLength < 0 {
+		errs = append(errs, &FieldError{Path: "maxTemplateLength", Err: errMustNotBeNegative})
+	}
+
+	return errors.Join(errs...)
+}
+
+// WithDefaults returns a copy of the limits, with unset values replaced by defaults.
+func (l Limits) WithDefaults() Limits {
+	if l.ExecutionTimeout == 0 {
+		l.ExecutionTimeout = DefaultExecutionTimeout
+	}
+	if l.MaxOutputSize == 0 {
+		l.MaxOutputSize = DefaultMaxOutputSize
+	}
+	if l.MaxTemplateLength == 0 {
+		l.MaxTemplateLength = DefaultMaxTemplateLength
+	}
+
+	return l
 }
 
 type Type struct {
-	APIVersion string `json:"apiVersion" yaml:"apiVersion"`
-	Kind       string `json:"kind" yaml:"kind"`
+	APIVersion string `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`
+	// Group is used instead of APIVersion to select the preferred version served by the cluster.
+	// It is empty, or "core", for the core group.
+	Group string `json:"group,omitempty" yaml:"group,omitempty"`
+	Kind  string `json:"kind" yaml:"kind"`
+	// Fields restricts the object fields that templates can read.
+	Fields Fields `json:"fields,omitempty" yaml:"fields,omitempty"`
+	// Filter selects the objects managed for this type, in addition to the global filter.
+	Filter `json:",inline" yaml:",inline"`
+	// Controller tunes the controller reconciling the type.
+	Controller ControllerOptions `json:"controller,omitempty" yaml:"controller,omitempty"`
+}
+
+const (
+	DefaultRateLimiterBaseDelay = 5 * time.Millisecond
+	DefaultRateLimiterMaxDelay  = 1000 * time.Second
+	DefaultRateLimiterQPS       = 10
+	DefaultRateLimiterBurst     = 100
+)
+
+// ControllerOptions tunes a controller. Zero values select the controller-runtime defaults.
+type ControllerOptions struct {
+	// MaxConcurrentReconciles is the number of objects reconciled in parallel (default: 1).
+	MaxConcurrentReconciles int `json:"maxConcurrentReconciles,omitempty" yaml:"maxConcurrentReconciles,omitempty"`
+	// RateLimiter limits how often objects are queued.
+	RateLimiter RateLimiter `json:"rateLimiter,omitempty" yaml:"rateLimiter,omitempty"`
+}
+
+// Validate checks that the options are not negative.
+func (o ControllerOptions) Validate() error {
+	var errs []error
+
+	if o.MaxConcurrentReconciles < 0 {
+		errs = append(errs, &FieldError{Path: "maxConcurrentReconciles", Err: errMustNotBeNegative})
+	}
+
+	errs = append(errs, withPath("rateLimiter", o.RateLimiter.Validate()))
+
+	return errors.Join(errs...)
+}
+
+// RateLimiter limits how often objects are queued. Failing objects are retried with an exponential
+// delay, between BaseDelay and MaxDelay, and all objects are queued at QPS, with bursts of Burst.
+type RateLimiter struct {
+	BaseDelay time.Duration `json:"baseDelay,omitempty" yaml:"baseDelay,omitempty"`
+	MaxDelay  time.Duration `json:"maxDelay,omitempty" yaml:"maxDelay,omitempty"`
+	QPS       float64       `json:"qps,omitempty" yaml:"qps,omitempty"`
+	Burst     int           `json:"burst,omitempty" yaml:"burst,omitempty"`
+}
+
+// IsZero reports whether the rate limiter is not configured.
+func (r RateLimiter) IsZero() bool {
+	return r == RateLimiter{}
 }
 
+// Validate checks that the values are not negative, and that the delays are ordered.
+func (r RateLimiter) Validate() error {
+	var errs []error
+
+	if r.BaseDelay < 0 {
+		errs = append(errs, &FieldError{Path: "baseDelay", Err: errMustNotBeNegative})
+	}
+	if r.MaxDelay < 0 {
+		errs = append(errs, &FieldError{Path: "maxDelay", Err: errMustNotBeNegative})
+	}
+	if r.QPS < 0 {
+		errs = append(errs, &FieldError{Path: "qps", Err: errMustNotBeNegative})
+	}
+	if r.Burst < 0 {
+		errs = append(errs, &FieldError{Path: "burst", Err: errMustNotBeNegative})
+	}
+
+	if d := r.WithDefaults(); d.MaxDelay < d.BaseDelay {
+		errs = append(errs, &FieldError{Path: "maxDelay", Err: errors.New("must not be less than baseDelay")})
+	}
+
+	return errors.Join(errs...)
+}
+
+// WithDefaults returns a copy of the rate limiter, with unset values replaced by the defaults.
+func (r RateLimiter) WithDefaults() RateLimiter {
+	if r.BaseDelay == 0 {
+		r.BaseDelay = DefaultRateLimiterBaseDelay
+	}
+	if r.MaxDelay == 0 {
+		r.MaxDelay = DefaultRateLimiterMaxDelay
+	}
+	if r.QPS == 0 {
+		r.QPS = DefaultRateLimiterQPS
+	}
+	if r.Burst == 0 {
+		r.Burst = DefaultRateLimiterBurst
+	}
+
+	return r
+}
+
+// GroupVersionKind returns the kind of the type. The version is empty
+// if the type has to be resolved to the preferred version.
 func (t *Type) GroupVersionKind() schema.GroupVersionKind {
+	if t.APIVersion == "" {
+		group := t.Group
+		if group == coreGroup {
+			group = ""
+		}
+
+		return schema.GroupVersionKind{Group: group, Kind: t.Kind}
+	}
+
 	return schema.FromAPIVersionAndKind(t.APIVersion, t.Kind)
 }
+
+// Unversioned reports whether the type has to be resolved to the preferred version.
+func (t *Type) Unversioned() bool {
+	return t.APIVersion == ""
+}
+
+// Validate checks that the type is well-formed.
+func (t *Type) Validate() error {
+	var errs []error
+
+	switch {
+	case t.Kind == "":
+		errs = append(errs, &FieldError{Path: "kind", Err: errors.New("must not be empty")})
+	case len(validation.IsDNS1035Label(strings.ToLower(t.Kind))) > 0:
+		errs = append(errs, &FieldError{Path: "kind", Err: fmt.Errorf("invalid kind %q", t.Kind)})
+	}
+
+	switch {
+	case t.APIVersion != "" && t.Group != "":
+		errs = append(errs, &FieldError{Path: "group", Err: errors.New("apiVersion and group are mutually exclusive")})
+
+	case t.APIVersion != "":
+		gv, err := schema.ParseGroupVersion(t.APIVersion)
+		if err != nil {
+			errs = append(errs, &FieldError{Path: "apiVersion", Err: err})
+			break
+		}
+		if gv.Group != "" && len(validation.IsDNS1123Subdomain(gv.Group)) > 0 {
+			errs = append(errs, &FieldError{Path: "apiVersion", Err: fmt.Errorf("invalid group %q", gv.Group)})
+		}
+		if len(validation.IsDNS1035Label(gv.Version)) > 0 {
+			errs = append(errs, &FieldError{Path: "apiVersion", Err: fmt.Errorf("invalid version %q", gv.Version)})
+		}
+
+	case t.Group != "" && len(validation.IsDNS1123Subdomain(t.Group)) > 0:
+		errs = append(errs, &FieldError{Path: "group", Err: fmt.Errorf("invalid group %q", t.Group)})
+	}
+
+	errs = append(errs, withPath("fields", t.Fields.Validate()))
+	errs = append(errs, t.Filter.Validate())
+	errs = append(errs, withPath("controller", t.Controller.Validate()))
+
+	return errors.Join(errs...)
+}
+
+// DefaultDeniedFields are hidden from templates, unless the deny list is set explicitly.
+var DefaultDeniedFields = []string{
+	"metadata.managedFields",
+	"metadata.annotations[kubectl.kubernetes.io/last-applied-configuration]",
+	"data",
+	"stringData",
+	"binaryData",
+}
+
+// Fields restricts the object fields passed to templates. Paths are dot-separated,
+// and keys containing dots are written in brackets, e.g. metadata.labels[app.kubernetes.io/name].
+type Fields struct {
+	// Allow lists the only fields that templates can read. If empty, all fields are allowed.
+	Allow []string `json:"allow,omitempty" yaml:"allow,omitempty"`
+	// Deny lists the fields that templates cannot read. If not set, DefaultDeniedFields are used.
+	// Setting it to an empty list disables the defaults.
+	Deny []string `json:"deny,omitempty" yaml:"deny,omitempty"`
+}
+
+// DeniedFields returns the deny list, falling back to DefaultDeniedFields when it is not set.
+func (f Fields) DeniedFields() []string {
+	if f.Deny == nil {
+		return DefaultDeniedFields
+	}
+
+	return f.Deny
+}
+
+// Validate checks that all field paths are well-formed.
+func (f Fields) Validate() error {
+	var errs []error
+
+	for i, path := range f.Allow {
+		if _, err := ParseFieldPath(path); err != nil {
+			errs = append(errs, &FieldError{Path: fmt.Sprintf("allow[%d]", i), Err: err})
+		}
+	}
+	for i, path := range f.Deny {
+		if _, err := ParseFieldPath(path); err != nil {
+			errs = append(errs, &FieldError{Path: fmt.Sprintf("deny[%d]", i), Err: err})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ParseFieldPath splits the field path into its segments.
+func ParseFieldPath(path string) ([]string, error) {
+	var (
+		segments []string
+		current  strings.Builder
+		inKey    bool
+		closed   bool
+	)
+
+	for _, r := range path {
+		switch {
+		case inKey && r == ']':
+			if current.Len() == 0 {
+				return nil, fmt.Errorf("invalid field path %q: empty key", path)
+			}
+			segments = append(segments, current.String())
+			current.Reset()
+			inKey = false
+			closed = true
+
+		case inKey:
+			current.WriteRune(r)
+
+		case r == '[':
+			if current.Len() > 0 {
+				segments = append(segments, current.String())
+				current.Reset()
+			} else if !closed && len(segments) > 0 {
+				return nil, fmt.Errorf("invalid field path %q: empty segment", path)
+			}
+			inKey = true
+			closed = false
+
+		case r == '.':
+			if current.Len() == 0 && !closed {
+				return nil, fmt.Errorf("invalid field path %q: empty segment", path)
+			}
+			if current.Len() > 0 {
+				segments = append(segments, current.String())
+				current.Reset()
+			}
+			closed = false
+
+		default:
+			if closed {
+				return nil, fmt.Errorf("invalid field path %q: expected '.' after ']'", path)
+			}
+			current.WriteRune(r)
+		}
+	}
+
+	if inKey {
+		return nil, fmt.Errorf("invalid field path %q: unterminated '['", path)
+	}
+	if current.Len() > 0 {
+		segments = append(segments, current.String())
+	} else if !closed {
+		return nil, fmt.Errorf("invalid field path %q: empty segment", path)
+	}
+
+	return segments, nil
+}
@@ -1,9 +1,12 @@
 package config
 
 import (
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	yaml "sigs.k8s.io/yaml/goyaml.v3"
 )
 
@@ -51,3 +54,491 @@ types:
 		return
 	}
 }
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		config      Config
+		expectError bool
+	}{
+		"empty": {
+			config: Config{},
+		},
+		"valid templates": {
+			config: Config{
+				Templates: map[string]string{
+					"cost-labels": `cost-center={{ index .metadata.labels "cost-center" }}`,
+				},
+			},
+		},
+		"broken template": {
+			config: Config{
+				Templates: map[string]string{
+					"cost-labels": `cost-center={{ .metadata.labels`,
+				},
+			},
+			expectError: true,
+		},
+		"negative limits": {
+			config: Config{
+				Limits: Limits{
+					ExecutionTimeout:  -1,
+					MaxOutputSize:     -1,
+					MaxTemplateLength: -1,
+				},
+			},
+			expectError: true,
+		},
+		"invalid field path": {
+			config: Config{
+				Types: []Type{
+					{APIVersion: "v1", Kind: "Pod", Fields: Fields{Deny: []string{"metadata..name"}}},
+				},
+			},
+			expectError: true,
+		},
+		"group and kind": {
+			config: Config{
+				Types: []Type{
+					{Group: "apps", Kind: "Deployment"},
+					{Kind: "Pod"},
+				},
+			},
+		},
+		"apiVersion and group": {
+			config: Config{
+				Types: []Type{
+					{APIVersion: "apps/v1", Group: "apps", Kind: "Deployment"},
+				},
+			},
+			expectError: true,
+		},
+		"missing kind": {
+			config: Config{
+				Types: []Type{
+					{APIVersion: "apps/v1"},
+				},
+			},
+			expectError: true,
+		},
+		"empty template name": {
+			config: Config{
+				Templates: map[string]string{
+					"": `foo=bar`,
+				},
+			},
+			expectError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.config.Validate()
+			if tc.expectError && err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+			if !tc.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestSharedTemplates(t *testing.T) {
+	t.Parallel()
+
+	cfg := Config{
+		Templates: map[string]string{
+			"first":  `first={{ .name }}`,
+			"second": `{{ template "first" . }},second=value`,
+		},
+	}
+
+	tpl, err := cfg.SharedTemplates()
+	if err != nil {
+		t.Errorf("Unexpected error while parsing templates: %v", err)
+		return
+	}
+
+	for _, name := range []string{"first", "second"} {
+		if tpl.Lookup(name) == nil {
+			t.Errorf("Expected template %q to be defined", name)
+		}
+	}
+}
+
+func TestLookupType(t *testing.T) {
+	t.Parallel()
+
+	cfg := Config{
+		Types: []Type{
+			{APIVersion: "apps/v1", Kind: "Deployment", Fields: Fields{Deny: []string{"spec"}}},
+			{Kind: "Pod"},
+		},
+		Include: []TypeSelector{{Group: "*.example.com"}},
+		Exclude: []TypeSelector{{Kind: "Secret*"}},
+	}
+
+	for name, tc := range map[string]struct {
+		gvk           schema.GroupVersionKind
+		expectedFound bool
+		expectedDeny  int
+	}{
+		"configured type":     {gvk: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, expectedFound: true, expectedDeny: 1},
+		"other version":       {gvk: schema.GroupVersionKind{Group: "apps", Version: "v1beta1", Kind: "Deployment"}},
+		"unversioned type":    {gvk: schema.GroupVersionKind{Version: "v1", Kind: "Pod"}, expectedFound: true},
+		"included type":       {gvk: schema.GroupVersionKind{Group: "widgets.example.com", Version: "v1", Kind: "Widget"}, expectedFound: true},
+		"excluded type":       {gvk: schema.GroupVersionKind{Group: "vault.example.com", Version: "v1", Kind: "SecretStore"}},
+		"type not configured": {gvk: schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			typ, found := cfg.LookupType(tc.gvk)
+			if found != tc.expectedFound {
+				t.Errorf("Unexpected result: expected %v, got %v", tc.expectedFound, found)
+			}
+			if len(typ.Fields.Deny) != tc.expectedDeny {
+				t.Errorf("Unexpected denied fields: expected %v, got %v", tc.expectedDeny, typ.Fields.Deny)
+			}
+		})
+	}
+}
+
+func TestUnmarshallLimits(t *testing.T) {
+	t.Parallel()
+
+	configFile := strings.NewReader(`---
+limits:
+  executionTimeout: 500ms
+  maxOutputSize: 1024
+`)
+
+	cfg := Config{}
+
+	err := yaml.NewDecoder(configFile).Decode(&cfg)
+	if err != nil {
+		t.Errorf("Unexpected error while decoding config: %v", err)
+		return
+	}
+
+	limits := cfg.Limits.WithDefaults()
+
+	if limits.ExecutionTimeout != 500*time.Millisecond {
+		t.Errorf("Unexpected executionTimeout: expected %v, got %v", 500*time.Millisecond, limits.ExecutionTimeout)
+	}
+
+	if limits.MaxOutputSize != 1024 {
+		t.Errorf("Unexpected maxOutputSize: expected %v, got %v", 1024, limits.MaxOutputSize)
+	}
+
+	if limits.MaxTemplateLength != DefaultMaxTemplateLength {
+		t.Errorf("Unexpected maxTemplateLength: expected %v, got %v", DefaultMaxTemplateLength, limits.MaxTemplateLength)
+	}
+}
+
+func TestParseFieldPath(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		path        string
+		expected    []string
+		expectError bool
+	}{
+		"single segment":          {path: "data", expected: []string{"data"}},
+		"nested":                  {path: "metadata.managedFields", expected: []string{"metadata", "managedFields"}},
+		"bracketed key":           {path: "metadata.labels[app.kubernetes.io/name]", expected: []string{"metadata", "labels", "app.kubernetes.io/name"}},
+		"bracketed key in middle": {path: "spec[a.b].c", expected: []string{"spec", "a.b", "c"}},
+		"empty":                   {path: "", expectError: true},
+		"empty segment":           {path: "metadata..name", expectError: true},
+		"trailing dot":            {path: "metadata.", expectError: true},
+		"empty key":               {path: "metadata[]", expectError: true},
+		"unterminated key":        {path: "metadata[name", expectError: true},
+		"missing dot after key":   {path: "metadata[name]labels", expectError: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := ParseFieldPath(tc.path)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+
+			if !slices.Equal(tc.expected, result) {
+				t.Errorf("Unexpected segments: expected %v, got %v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		input       string
+		expectError bool
+	}{
+		"valid": {
+			input: `---
+types:
+- apiVersion: apps/v1
+  kind: Deployment
+`,
+		},
+		"empty": {
+			input: "",
+		},
+		"malformed": {
+			input:       `types: [`,
+			expectError: true,
+		},
+		"invalid": {
+			input: `---
+templates:
+  broken: "{{ .metadata"
+`,
+			expectError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Parse([]byte(tc.input))
+			if tc.expectError && err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+			if !tc.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestParseCompilesValuePatterns(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Parse([]byte(`---
+policy:
+  values:
+  - keys:
+    - cost-center
+    pattern: "^[0-9]{6}$"
+  - keys:
+    - env
+    enum:
+    - prod
+`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if cfg.Policy.Values[0].pattern == nil {
+		t.Errorf("Expected the pattern to be compiled")
+	}
+	if cfg.Policy.Values[1].pattern != nil {
+		t.Errorf("Unexpected pattern compiled for a rule without pattern")
+	}
+	if err := cfg.Policy.Values[0].Check("12345"); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+func TestFieldErrors(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		input    string
+		expected []string
+	}{
+		"valid": {
+			input: `---
+types:
+- apiVersion: apps/v1
+  kind: Deployment
+`,
+		},
+		"filters": {
+			input: `---
+excludedNamespaces: [kube-system]
+types:
+- apiVersion: apps/v1
+  kind: Deployment
+  objectSelector:
+    matchLabels:
+      app.kubernetes.io/managed-by: helm
+  namespaceSelector:
+    matchExpressions:
+    - key: team
+      operator: Exists
+`,
+		},
+		"invalid filters": {
+			input: `---
+namespaces: ["[kube"]
+types:
+- apiVersion: apps/v1
+  kind: Deployment
+  objectSelector:
+    matchExpressions:
+    - key: team
+      operator: Equals
+`,
+			expected: []string{"namespaces[0]", "types[0].objectSelector"},
+		},
+		"controller options": {
+			input: `---
+types:
+- apiVersion: apps/v1
+  kind: Deployment
+  controller:
+    maxConcurrentReconciles: 8
+    rateLimiter:
+      baseDelay: 10ms
+      maxDelay: 5m
+      qps: 50
+      burst: 200
+`,
+		},
+		"invalid controller options": {
+			input: `---
+types:
+- apiVersion: apps/v1
+  kind: Deployment
+  controller:
+    maxConcurrentReconciles: -1
+    rateLimiter:
+      baseDelay: 1h
+      maxDelay: 1m
+`,
+			expected: []string{"types[0].controller.maxConcurrentReconciles", "types[0].controller.rateLimiter.maxDelay"},
+		},
+		"unknown field": {
+			input: `---
+types:
+- apiVersion: apps/v1
+  kidn: Deployment
+`,
+			expected: []string{"types[0].kidn"},
+		},
+		"duplicate field": {
+			input: `---
+types:
+- apiVersion: apps/v1
+  kind: Deployment
+  kind: StatefulSet
+`,
+			expected: []string{"types[0].kind"},
+		},
+		"duplicate type": {
+			input: `---
+types:
+- apiVersion: apps/v1
+  kind: Deployment
+- group: apps
+  kind: Deployment
+`,
+			expected: []string{"types[1]"},
+		},
+		"malformed kinds": {
+			input: `---
+types:
+- apiVersion: apps/v1
+- apiVersion: Apps/v1
+  kind: Deployment
+- apiVersion: apps/v1
+  kind: Deploy.ment
+- group: apps
+  apiVersion: apps/v1
+  kind: StatefulSet
+`,
+			expected: []string{"types[0].kind", "types[1].apiVersion", "types[2].kind", "types[3].group"},
+		},
+		"policy": {
+			input: `---
+policy:
+  deniedKeys:
+  - kubernetes.io/*
+  - ""
+  namespaced:
+  - namespaceSelector:
+      matchLabels:
+        tenant: "true"
+    allowedKeys:
+    - "[team"
+  restricted:
+  - keys:
+    - sidecar.istio.io/*
+  - groups:
+    - platform
+  values:
+  - keys:
+    - team
+    pattern: "[a-z"
+  - keys:
+    - owner
+    maxLength: -1
+  - keys:
+    - tier
+`,
+			expected: []string{
+				"policy.deniedKeys[1]",
+				"policy.namespaced[0].allowedKeys[0]",
+				"policy.restricted[0].groups",
+				"policy.restricted[1].keys",
+				"policy.values[0].pattern",
+				"policy.values[1].maxLength",
+				"policy.values[2]",
+			},
+		},
+		"nested paths": {
+			input: `---
+types:
+- apiVersion: v1
+  kind: Pod
+  fields:
+    deny:
+    - metadata..name
+limits:
+  maxOutputSize: -1
+include:
+- group: "[apps"
+templates:
+  broken: "{{ .metadata"
+`,
+			expected: []string{
+				"include[0].group",
+				"limits.maxOutputSize",
+				"templates.broken",
+				"types[0].fields.deny[0]",
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg, err := Decode([]byte(tc.input))
+			if err == nil {
+				err = cfg.Validate()
+			}
+
+			var paths []string
+			for _, fe := range FieldErrors(err) {
+				paths = append(paths, fe.Path)
+			}
+			slices.Sort(paths)
+
+			if !slices.Equal(tc.expected, paths) {
+				t.Errorf("Unexpected paths: expected %v, got %v (%v)", tc.expected, paths, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	yaml "sigs.k8s.io/yaml/goyaml.v3"
+)
+
+// FieldError is a problem with a single field of the configuration, identified by its YAML path,
+// e.g. types[0].fields.allow[1].
+type FieldError struct {
+	Path string
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// FieldErrors flattens the errors returned by Decode and Validate into a list of field errors.
+// Errors that are not related to a single field are returned with an empty path.
+func FieldErrors(err error) []*FieldError {
+	return prefixErrors("", err)
+}
+
+// withPath prefixes the paths of all field errors in err with the given path.
+func withPath(path string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	fieldErrs := prefixErrors(path, err)
+
+	errs := make([]error, 0, len(fieldErrs))
+	for _, fe := range fieldErrs {
+		errs = append(errs, fe)
+	}
+
+	return errors.Join(errs...)
+}
+
+func prefixErrors(prefix string, err error) []*FieldError {
+	switch e := err.(type) {
+	case nil:
+		return nil
+
+	case interface{ Unwrap() []error }:
+		var errs []*FieldError
+		for _, inner := range e.Unwrap() {
+			errs = append(errs, prefixErrors(prefix, inner)...)
+		}
+		return errs
+
+	case *FieldError:
+		return prefixErrors(joinPath(prefix, e.Path), e.Err)
+
+	default:
+		return []*FieldError{{Path: prefix, Err: err}}
+	}
+}
+
+func joinPath(prefix, path string) string {
+	switch {
+	case prefix == "":
+		return path
+	case path == "":
+		return prefix
+	case strings.HasPrefix(path, "["):
+		return prefix + path
+	default:
+		return prefix + "." + path
+	}
+}
+
+// lineError matches the per-line messages of yaml.TypeError.
+var lineError = regexp.MustCompile(`^line (\d+): (.*)$`)
+
+// decodeErrors converts the decoding errors to field errors, finding the path of every
+// reported line in the document. Errors that cannot be mapped to a path are returned as is.
+func decodeErrors(data []byte, err error) error {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return err
+	}
+
+	var root yaml.Node
+	paths := map[int]string{}
+	if yaml.Unmarshal(data, &root) == nil {
+		linePaths(&root, "", paths)
+	}
+
+	errs := make([]error, 0, len(typeErr.Errors))
+	for _, msg := range typeErr.Errors {
+		m := lineError.FindStringSubmatch(msg)
+		if m == nil {
+			errs = append(errs, errors.New(msg))
+			continue
+		}
+
+		line, _ := strconv.Atoi(m[1])
+		errs = append(errs, &FieldError{
+			Path: paths[line],
+			Err:  fmt.Errorf("%s (line %d)", m[2], line),
+		})
+	}
+
+	return errors.Join(errs...)
+}
+
+// linePaths records the path of the deepest node starting at every line of the document.
+func linePaths(node *yaml.Node, path string, paths map[int]string) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, n := range node.Content {
+			linePaths(n, path, paths)
+		}
+
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			keyPath := joinPath(path, key.Value)
+			paths[key.Line] = keyPath
+			linePaths(value, keyPath, paths)
+		}
+
+	case yaml.SequenceNode:
+		for i, n := range node.Content {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+			paths[n.Line] = itemPath
+			linePaths(n, itemPath, paths)
+		}
+	}
+}
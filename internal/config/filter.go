@@ -0,0 +1,118 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Filter selects the objects managed by the controller. It can be set globally,
+// and for every type, in which case an object must be selected by both.
+type Filter struct {
+	// ObjectSelector selects objects by their labels.
+	ObjectSelector *LabelSelector `json:"objectSelector,omitempty" yaml:"objectSelector,omitempty"`
+	// NamespaceSelector selects objects by the labels of their namespace.
+	NamespaceSelector *LabelSelector `json:"namespaceSelector,omitempty" yaml:"namespaceSelector,omitempty"`
+	// Namespaces lists the only namespaces whose objects are managed. Names can be glob patterns.
+	// If empty, all namespaces are included.
+	Namespaces []string `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+	// ExcludedNamespaces lists the namespaces whose objects are never managed. Names can be glob patterns.
+	ExcludedNamespaces []string `json:"excludedNamespaces,omitempty" yaml:"excludedNamespaces,omitempty"`
+}
+
+// Validate checks that the selectors and the namespace patterns are well-formed.
+func (f Filter) Validate() error {
+	var errs []error
+
+	if _, err := f.ObjectSelector.Selector(); err != nil {
+		errs = append(errs, &FieldError{Path: "objectSelector", Err: err})
+	}
+	if _, err := f.NamespaceSelector.Selector(); err != nil {
+		errs = append(errs, &FieldError{Path: "namespaceSelector", Err: err})
+	}
+
+	for i, ns := range f.Namespaces {
+		if _, err := path.Match(ns, ""); err != nil {
+			errs = append(errs, &FieldError{Path: fmt.Sprintf("namespaces[%d]", i), Err: fmt.Errorf("invalid pattern %q: %w", ns, err)})
+		}
+	}
+	for i, ns := range f.ExcludedNamespaces {
+		if _, err := path.Match(ns, ""); err != nil {
+			errs = append(errs, &FieldError{Path: fmt.Sprintf("excludedNamespaces[%d]", i), Err: fmt.Errorf("invalid pattern %q: %w", ns, err)})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// IncludesNamespace reports whether objects in the namespace with the given name can be managed.
+// Namespace labels are not taken into account.
+func (f Filter) IncludesNamespace(name string) bool {
+	for _, pattern := range f.ExcludedNamespaces {
+		if matchPattern(pattern, name) {
+			return false
+		}
+	}
+
+	if len(f.Namespaces) == 0 {
+		return true
+	}
+
+	for _, pattern := range f.Namespaces {
+		if matchPattern(pattern, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// LabelSelector is a label query, with the same semantics as the Kubernetes LabelSelector.
+type LabelSelector struct {
+	MatchLabels      map[string]string          `json:"matchLabels,omitempty" yaml:"matchLabels,omitempty"`
+	MatchExpressions []LabelSelectorRequirement `json:"matchExpressions,omitempty" yaml:"matchExpressions,omitempty"`
+}
+
+// LabelSelectorRequirement is a single requirement of a LabelSelector.
+type LabelSelectorRequirement struct {
+	Key      string   `json:"key" yaml:"key"`
+	Operator string   `json:"operator" yaml:"operator"`
+	Values   []string `json:"values,omitempty" yaml:"values,omitempty"`
+}
+
+// Selector converts the label selector to a labels.Selector. A nil selector selects everything.
+func (s *LabelSelector) Selector() (labels.Selector, error) {
+	if s == nil {
+		return labels.Everything(), nil
+	}
+
+	ls := &metav1.LabelSelector{MatchLabels: s.MatchLabels}
+	for _, req := range s.MatchExpressions {
+		ls.MatchExpressions = append(ls.MatchExpressions, metav1.LabelSelectorRequirement{
+			Key:      req.Key,
+			Operator: metav1.LabelSelectorOperator(req.Operator),
+			Values:   req.Values,
+		})
+	}
+
+	return metav1.LabelSelectorAsSelector(ls)
+}
@@ -0,0 +1,302 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Policy restricts the keys propagated from namespaces to objects. The global key policy
+// applies to all namespaces, and namespaced key policies to the namespaces they select;
+// a key must be accepted by every policy that applies.
+type Policy struct {
+	KeyPolicy `json:",inline" yaml:",inline"`
+	// Namespaced lists key policies applied to the namespaces selected by their labels.
+	Namespaced []NamespacedKeyPolicy `json:"namespaced,omitempty" yaml:"namespaced,omitempty"`
+	// Restricted lists the keys that only members of some groups can add to namespace blocks.
+	// It is enforced by the admission webhook, so restricted keys are only propagated if they
+	// are written literally in the block, where the webhook can see them.
+	Restricted []RestrictedKeys `json:"restricted,omitempty" yaml:"restricted,omitempty"`
+	// Values lists constraints on the values of the keys. A key matching several rules must satisfy all of them.
+	Values []ValueRule `json:"values,omitempty" yaml:"values,omitempty"`
+}
+
+// ValueRule constrains the values of the keys. All constraints that are set must be satisfied.
+type ValueRule struct {
+	// Keys lists the constrained keys, as glob patterns.
+	Keys []string `json:"keys" yaml:"keys"`
+	// Pattern is a regular expression the values must match, e.g. "^[0-9]{6}$".
+	Pattern string `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	// Enum lists the only accepted values.
+	Enum []string `json:"enum,omitempty" yaml:"enum,omitempty"`
+	// MaxLength is the maximum length of the values, in characters.
+	MaxLength int `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+
+	// pattern is the compiled Pattern, set once the rule is validated by Parse.
+	pattern *regexp.Regexp
+}
+
+// RestrictedKeys restricts who can add the keys to namespace blocks, or change their values.
+type RestrictedKeys struct {
+	// Keys lists the restricted keys, as glob patterns.
+	Keys []string `json:"keys" yaml:"keys"`
+	// Groups lists the groups whose members can add the keys.
+	Groups []string `json:"groups" yaml:"groups"`
+}
+
+// NamespacedKeyPolicy is a key policy applied to the namespaces selected by their labels.
+type NamespacedKeyPolicy struct {
+	// NamespaceSelector selects the namespaces. If not set, all namespaces are selected.
+	NamespaceSelector *LabelSelector `json:"namespaceSelector,omitempty" yaml:"namespaceSelector,omitempty"`
+	KeyPolicy         `json:",inline" yaml:",inline"`
+}
+
+// KeyPolicy allows and denies keys by glob patterns, e.g. "kubernetes.io/*" or "*.istio.io/*".
+type KeyPolicy struct {
+	// AllowedKeys lists the only keys that can be propagated. If empty, all keys are allowed.
+	AllowedKeys []string `json:"allowedKeys,omitempty" yaml:"allowedKeys,omitempty"`
+	// DeniedKeys lists the keys that are never propagated. It takes precedence over AllowedKeys.
+	DeniedKeys []string `json:"deniedKeys,omitempty" yaml:"deniedKeys,omitempty"`
+}
+
+// Validate checks that the patterns and the namespace selectors are well-formed.
+func (p Policy) Validate() error {
+	errs := []error{p.KeyPolicy.Validate()}
+
+	for i, np := range p.Namespaced {
+		path := fmt.Sprintf("namespaced[%d]", i)
+
+		if _, err := np.NamespaceSelector.Selector(); err != nil {
+			errs = append(errs, &FieldError{Path: joinPath(path, "namespaceSelector"), Err: err})
+		}
+		errs = append(errs, withPath(path, np.KeyPolicy.Validate()))
+	}
+
+	for i, r := range p.Restricted {
+		errs = append(errs, withPath(fmt.Sprintf("restricted[%d]", i), r.Validate()))
+	}
+
+	for i, r := range p.Values {
+		errs = append(errs, withPath(fmt.Sprintf("values[%d]", i), r.Validate()))
+	}
+
+	return errors.Join(errs...)
+}
+
+// compile compiles the patterns of the value rules, so they are not compiled again for every
+// checked value. Invalid patterns are left uncompiled, as they are reported by Validate.
+func (p *Policy) compile() {
+	for i, r := range p.Values {
+		if r.Pattern != "" {
+			p.Values[i].pattern, _ = regexp.Compile(r.Pattern)
+		}
+	}
+}
+
+// Authorize returns an error if the key is restricted, and none of the groups can add it.
+// A key matching several restrictions must be allowed by each of them.
+func (p Policy) Authorize(key string, groups []string) error {
+	for _, r := range p.Restricted {
+		if r.Matches(key) && !r.Allows(groups) {
+			return fmt.Errorf("key is restricted to members of the groups: %s", strings.Join(r.Groups, ", "))
+		}
+	}
+
+	return nil
+}
+
+// Restricts reports whether the key matches any restriction.
+func (p Policy) Restricts(key string) bool {
+	return slices.ContainsFunc(p.Restricted, func(r RestrictedKeys) bool {
+		return r.Matches(key)
+	})
+}
+
+// Validate checks that the patterns are well-formed, and that groups are set.
+func (r RestrictedKeys) Validate() error {
+	var errs []error
+
+	if len(r.Keys) == 0 {
+		errs = append(errs, &FieldError{Path: "keys", Err: errors.New("must not be empty")})
+	}
+	for i, pattern := range r.Keys {
+		errs = append(errs, withPath(fmt.Sprintf("keys[%d]", i), validateKeyPattern(pattern)))
+	}
+
+	if len(r.Groups) == 0 {
+		errs = append(errs, &FieldError{Path: "groups", Err: errors.New("must not be empty")})
+	}
+
+	return errors.Join(errs...)
+}
+
+// Matches reports whether the key is restricted.
+func (r RestrictedKeys) Matches(key string) bool {
+	for _, pattern := range r.Keys {
+		if matchPattern(pattern, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Allows reports whether any of the groups can add the keys.
+func (r RestrictedKeys) Allows(groups []string) bool {
+	for _, group := range groups {
+		if slices.Contains(r.Groups, group) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validate checks that the patterns are well-formed, and that a constraint is set.
+func (r ValueRule) Validate() error {
+	var errs []error
+
+	if len(r.Keys) == 0 {
+		errs = append(errs, &FieldError{Path: "keys", Err: errors.New("must not be empty")})
+	}
+	for i, pattern := range r.Keys {
+		errs = append(errs, withPath(fmt.Sprintf("keys[%d]", i), validateKeyPattern(pattern)))
+	}
+
+	if _, err := regexp.Compile(r.Pattern); err != nil {
+		errs = append(errs, &FieldError{Path: "pattern", Err: fmt.Errorf("invalid regular expression: %w", err)})
+	}
+
+	if r.MaxLength < 0 {
+		errs = append(errs, &FieldError{Path: "maxLength", Err: errMustNotBeNegative})
+	}
+
+	if r.Pattern == "" && len(r.Enum) == 0 && r.MaxLength == 0 {
+		errs = append(errs, errors.New("one of pattern, enum or maxLength must be set"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Matches reports whether the key is constrained by the rule.
+func (r ValueRule) Matches(key string) bool {
+	for _, pattern := range r.Keys {
+		if matchPattern(pattern, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Check returns an error for every constraint the value does not satisfy.
+// Patterns are validated upfront, so invalid patterns are treated as matching nothing.
+func (r ValueRule) Check(value string) error {
+	var errs []error
+
+	if r.Pattern != "" {
+		pattern := r.pattern
+		if pattern == nil {
+			// Rules that were not parsed, e.g. built in code, are compiled on every check.
+			pattern, _ = regexp.Compile(r.Pattern)
+		}
+		if pattern == nil || !pattern.MatchString(value) {
+			errs = append(errs, fmt.Errorf("value %q does not match the pattern %q", value, r.Pattern))
+		}
+	}
+
+	if len(r.Enum) > 0 && !slices.Contains(r.Enum, value) {
+		errs = append(errs, fmt.Errorf("value %q is not one of: %s", value, strings.Join(r.Enum, ", ")))
+	}
+
+	if r.MaxLength > 0 && utf8.RuneCountInString(value) > r.MaxLength {
+		errs = append(errs, fmt.Errorf("value is longer than %d characters", r.MaxLength))
+	}
+
+	return errors.Join(errs...)
+}
+
+// ForNamespace returns the key policies that apply to a namespace with the given labels.
+// Selectors are validated upfront, so invalid selectors are treated as selecting nothing.
+func (p Policy) ForNamespace(nsLabels map[string]string) []KeyPolicy {
+	policies := []KeyPolicy{p.KeyPolicy}
+
+	for _, np := range p.Namespaced {
+		selector, err := np.NamespaceSelector.Selector()
+		if err != nil || !selector.Matches(labels.Set(nsLabels)) {
+			continue
+		}
+		policies = append(policies, np.KeyPolicy)
+	}
+
+	return policies
+}
+
+// Validate checks that all patterns are well-formed.
+func (p KeyPolicy) Validate() error {
+	var errs []error
+
+	for i, pattern := range p.AllowedKeys {
+		errs = append(errs, withPath(fmt.Sprintf("allowedKeys[%d]", i), validateKeyPattern(pattern)))
+	}
+	for i, pattern := range p.DeniedKeys {
+		errs = append(errs, withPath(fmt.Sprintf("deniedKeys[%d]", i), validateKeyPattern(pattern)))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Check returns an error if the key is denied, or not allowed, by the policy.
+func (p KeyPolicy) Check(key string) error {
+	for _, pattern := range p.DeniedKeys {
+		if matchPattern(pattern, key) {
+			return fmt.Errorf("key is denied by policy pattern %q", pattern)
+		}
+	}
+
+	if len(p.AllowedKeys) == 0 {
+		return nil
+	}
+
+	for _, pattern := range p.AllowedKeys {
+		if matchPattern(pattern, key) {
+			return nil
+		}
+	}
+
+	return errors.New("key is not allowed by policy")
+}
+
+func validateKeyPattern(pattern string) error {
+	if pattern == "" {
+		return errors.New("pattern must not be empty")
+	}
+
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	return nil
+}
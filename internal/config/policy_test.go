@@ -0,0 +1,158 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+)
+
+func TestKeyPolicyCheck(t *testing.T) {
+	t.Parallel()
+
+	policy := KeyPolicy{
+		AllowedKeys: []string{"team", "*.example.com/*", "sidecar.istio.io/*"},
+		DeniedKeys:  []string{"sidecar.istio.io/inject"},
+	}
+
+	for name, tc := range map[string]struct {
+		policy      KeyPolicy
+		key         string
+		expectError bool
+	}{
+		"empty policy":     {policy: KeyPolicy{}, key: "kubernetes.io/foo"},
+		"allowed":          {policy: policy, key: "team"},
+		"allowed prefix":   {policy: policy, key: "cost.example.com/center"},
+		"not allowed":      {policy: policy, key: "owner", expectError: true},
+		"denied":           {policy: policy, key: "sidecar.istio.io/inject", expectError: true},
+		"denied only":      {policy: KeyPolicy{DeniedKeys: []string{"kubernetes.io/*"}}, key: "kubernetes.io/foo", expectError: true},
+		"denied subdomain": {policy: KeyPolicy{DeniedKeys: []string{"*kubernetes.io/*"}}, key: "node.kubernetes.io/foo", expectError: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.policy.Check(tc.key)
+			if tc.expectError && err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+			if !tc.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestPolicyForNamespace(t *testing.T) {
+	t.Parallel()
+
+	policy := Policy{
+		KeyPolicy: KeyPolicy{DeniedKeys: []string{"kubernetes.io/*"}},
+		Namespaced: []NamespacedKeyPolicy{
+			{
+				NamespaceSelector: &LabelSelector{MatchLabels: map[string]string{"tenant": "true"}},
+				KeyPolicy:         KeyPolicy{DeniedKeys: []string{"sidecar.istio.io/*"}},
+			},
+			{
+				KeyPolicy: KeyPolicy{AllowedKeys: []string{"*"}},
+			},
+		},
+	}
+
+	if policies := policy.ForNamespace(map[string]string{"tenant": "true"}); len(policies) != 3 {
+		t.Errorf("Unexpected number of policies for tenant namespace: expected %v, got %v", 3, len(policies))
+	}
+
+	if policies := policy.ForNamespace(nil); len(policies) != 2 {
+		t.Errorf("Unexpected number of policies for other namespace: expected %v, got %v", 2, len(policies))
+	}
+}
+
+func TestPolicyAuthorize(t *testing.T) {
+	t.Parallel()
+
+	policy := Policy{
+		Restricted: []RestrictedKeys{
+			{Keys: []string{"sidecar.istio.io/*"}, Groups: []string{"platform", "mesh"}},
+			{Keys: []string{"sidecar.istio.io/inject"}, Groups: []string{"platform"}},
+		},
+	}
+
+	for name, tc := range map[string]struct {
+		key         string
+		groups      []string
+		expectError bool
+	}{
+		"unrestricted key":       {key: "team"},
+		"unrestricted no groups": {key: "team", groups: nil},
+		"member":                 {key: "sidecar.istio.io/proxyCPU", groups: []string{"system:authenticated", "mesh"}},
+		"not a member":           {key: "sidecar.istio.io/proxyCPU", groups: []string{"system:authenticated"}, expectError: true},
+		"no groups":              {key: "sidecar.istio.io/proxyCPU", expectError: true},
+		"member of all":          {key: "sidecar.istio.io/inject", groups: []string{"platform"}},
+		"member of one of many":  {key: "sidecar.istio.io/inject", groups: []string{"mesh"}, expectError: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if restricts := policy.Restricts(tc.key); restricts == (tc.key == "team") {
+				t.Errorf("Unexpected restriction of %q: %v", tc.key, restricts)
+			}
+
+			err := policy.Authorize(tc.key, tc.groups)
+			if tc.expectError && err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+			if !tc.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValueRuleCheck(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		rule           ValueRule
+		value          string
+		expectedErrors int
+	}{
+		"matches pattern":    {rule: ValueRule{Pattern: "^[0-9]{6}$"}, value: "123456"},
+		"mismatches pattern": {rule: ValueRule{Pattern: "^[0-9]{6}$"}, value: "12345", expectedErrors: 1},
+		"in enum":            {rule: ValueRule{Enum: []string{"dev", "prod"}}, value: "prod"},
+		"not in enum":        {rule: ValueRule{Enum: []string{"dev", "prod"}}, value: "test", expectedErrors: 1},
+		"within max length":  {rule: ValueRule{MaxLength: 4}, value: "żółw"},
+		"too long":           {rule: ValueRule{MaxLength: 4}, value: "turtle", expectedErrors: 1},
+		"all constraints": {
+			rule:           ValueRule{Pattern: "^[a-z]+$", Enum: []string{"a"}, MaxLength: 1},
+			value:          "B2",
+			expectedErrors: 3,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.rule.Check(tc.value)
+
+			var errs []error
+			if joined, ok := err.(interface{ Unwrap() []error }); ok {
+				errs = joined.Unwrap()
+			}
+			if len(errs) != tc.expectedErrors {
+				t.Errorf("Unexpected number of errors: expected %v, got %v (%v)", tc.expectedErrors, len(errs), err)
+			}
+		})
+	}
+}
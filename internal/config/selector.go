@@ -0,0 +1,81 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// coreGroup is the name used in selectors for the core API group, whose actual name is empty.
+const coreGroup = "core"
+
+// TypeSelector selects types through the discovery API. Group and Kind are glob patterns,
+// e.g. "*.example.com"; an empty pattern matches everything. The core API group is selected with "core".
+type TypeSelector struct {
+	Group string `json:"group,omitempty" yaml:"group,omitempty"`
+	Kind  string `json:"kind,omitempty" yaml:"kind,omitempty"`
+}
+
+// Matches reports whether the group and kind are selected.
+func (s TypeSelector) Matches(gk schema.GroupKind) bool {
+	group := gk.Group
+	if group == "" {
+		group = coreGroup
+	}
+
+	return matchPattern(s.Group, group) && matchPattern(s.Kind, gk.Kind)
+}
+
+// Validate checks that both patterns are well-formed.
+func (s TypeSelector) Validate() error {
+	var errs []error
+
+	if _, err := path.Match(s.Group, ""); err != nil {
+		errs = append(errs, &FieldError{Path: "group", Err: fmt.Errorf("invalid pattern %q: %w", s.Group, err)})
+	}
+	if _, err := path.Match(s.Kind, ""); err != nil {
+		errs = append(errs, &FieldError{Path: "kind", Err: fmt.Errorf("invalid pattern %q: %w", s.Kind, err)})
+	}
+
+	return errors.Join(errs...)
+}
+
+// matchPattern matches the value against the glob pattern. Patterns are validated upfront,
+// so errors are treated as no match.
+func matchPattern(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+
+	matched, err := path.Match(pattern, value)
+	return err == nil && matched
+}
+
+// MatchesAny reports whether any of the selectors matches the group and kind.
+func MatchesAny(selectors []TypeSelector, gk schema.GroupKind) bool {
+	for _, s := range selectors {
+		if s.Matches(gk) {
+			return true
+		}
+	}
+
+	return false
+}
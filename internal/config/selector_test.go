@@ -0,0 +1,74 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestTypeSelectorMatches(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		selector TypeSelector
+		gk       schema.GroupKind
+		expected bool
+	}{
+		"empty selector":        {selector: TypeSelector{}, gk: schema.GroupKind{Group: "apps", Kind: "Deployment"}, expected: true},
+		"exact group":           {selector: TypeSelector{Group: "apps"}, gk: schema.GroupKind{Group: "apps", Kind: "Deployment"}, expected: true},
+		"other group":           {selector: TypeSelector{Group: "apps"}, gk: schema.GroupKind{Group: "batch", Kind: "Job"}},
+		"core group":            {selector: TypeSelector{Group: "core"}, gk: schema.GroupKind{Kind: "Pod"}, expected: true},
+		"wildcard group":        {selector: TypeSelector{Group: "*.example.com", Kind: "*"}, gk: schema.GroupKind{Group: "foo.example.com", Kind: "Bar"}, expected: true},
+		"wildcard not matching": {selector: TypeSelector{Group: "*.example.com"}, gk: schema.GroupKind{Group: "example.com", Kind: "Bar"}},
+		"kind":                  {selector: TypeSelector{Group: "apps", Kind: "Deployment"}, gk: schema.GroupKind{Group: "apps", Kind: "StatefulSet"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if result := tc.selector.Matches(tc.gk); result != tc.expected {
+				t.Errorf("Unexpected result: expected %v, got %v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestTypeSelectorValidate(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		selector    TypeSelector
+		expectError bool
+	}{
+		"valid":         {selector: TypeSelector{Group: "*.example.com", Kind: "*"}},
+		"invalid group": {selector: TypeSelector{Group: "[apps"}, expectError: true},
+		"invalid kind":  {selector: TypeSelector{Kind: "[Deployment"}, expectError: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.selector.Validate()
+			if tc.expectError && err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+			if !tc.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
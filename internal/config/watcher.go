@@ -0,0 +1,128 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Watcher reloads the configuration file whenever it changes, and passes every
+// valid configuration to the callback. Invalid configurations are logged and ignored,
+// so the previous configuration stays in effect.
+type Watcher struct {
+	path     string
+	onChange func(*Config) error
+	last     []byte
+}
+
+// NewWatcher creates a Watcher for the configuration file at the given path.
+func NewWatcher(path string, onChange func(*Config) error) *Watcher {
+	return &Watcher{
+		path:     path,
+		onChange: onChange,
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. The configuration
+// is watched on every replica, so it is up to date when leadership changes.
+func (w *Watcher) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable. It blocks until the context is done.
+func (w *Watcher) Start(ctx context.Context) error {
+	log := log.FromContext(ctx, "path", w.path)
+
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return fmt.Errorf("unable to read the config file: %w", err)
+	}
+	w.last = data
+
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("unable to create file watcher: %w", err)
+	}
+	defer fw.Close() //nolint:errcheck // best effort close
+
+	// The directory is watched instead of the file, because files mounted from a ConfigMap
+	// are replaced by swapping symlinks, which is not reported on the file itself.
+	if err := fw.Add(filepath.Dir(w.path)); err != nil {
+		return fmt.Errorf("unable to watch the config file: %w", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case err, ok := <-fw.Errors:
+			if !ok {
+				return nil
+			}
+			log.Error(err, "Config file watcher error")
+
+		case _, ok := <-fw.Events:
+			if !ok {
+				return nil
+			}
+			w.reload(ctx)
+		}
+	}
+}
+
+// reload reads the configuration file and calls the callback if the content changed.
+func (w *Watcher) reload(ctx context.Context) {
+	log := log.FromContext(ctx, "path", w.path)
+
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		log.Error(err, "Unable to read the config file, keeping the previous config")
+		return
+	}
+
+	if bytes.Equal(data, w.last) {
+		return
+	}
+
+	// An empty file is most likely being written, and would stop all controllers.
+	if len(bytes.TrimSpace(data)) == 0 {
+		log.Info("Config file is empty, keeping the previous config")
+		return
+	}
+
+	cfg, err := Parse(data)
+	if err != nil {
+		log.Error(err, "Invalid config file, keeping the previous config")
+		return
+	}
+
+	w.last = data
+
+	log.Info("Config file changed, applying")
+	if err := w.onChange(cfg); err != nil {
+		log.Error(err, "Unable to fully apply the config")
+	}
+}
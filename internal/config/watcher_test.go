@@ -0,0 +1,100 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcher(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	// Files are replaced atomically, like files mounted from a ConfigMap.
+	writeFile := func(content string) {
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, []byte(content), 0o600); err != nil {
+			t.Fatalf("Unable to write config file: %v", err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			t.Fatalf("Unable to replace config file: %v", err)
+		}
+	}
+
+	writeFile(`---
+types:
+- apiVersion: v1
+  kind: Pod
+`)
+
+	changes := make(chan *Config, 10)
+	w := NewWatcher(path, func(cfg *Config) error {
+		changes <- cfg
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan error, 1)
+	go func() {
+		started <- w.Start(ctx)
+	}()
+
+	// Rewrite the file until the watcher picks up the change, as the watcher
+	// may not be watching yet when the file is written for the first time.
+	deadline := time.After(10 * time.Second)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	// Invalid configs must be ignored.
+	writeFile(`---
+limits:
+  maxOutputSize: -1
+`)
+
+	for {
+		select {
+		case cfg := <-changes:
+			if len(cfg.Types) != 2 {
+				t.Errorf("Unexpected length of Types: expected %v, got %v", 2, len(cfg.Types))
+			}
+			cancel()
+			if err := <-started; err != nil {
+				t.Errorf("Unexpected error from watcher: %v", err)
+			}
+			return
+
+		case <-ticker.C:
+			writeFile(`---
+types:
+- apiVersion: v1
+  kind: Pod
+- apiVersion: apps/v1
+  kind: Deployment
+`)
+
+		case <-deadline:
+			t.Fatalf("Timed out waiting for config change")
+		}
+	}
+}
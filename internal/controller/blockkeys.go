@@ -0,0 +1,158 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"slices"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// blockKeys describes the keys of a namespace block, as written in its template.
+type blockKeys struct {
+	// literal are the keys written literally in the block, e.g. "team" in "team={{ .metadata.name }}",
+	// including those in conditional and loop branches, and in the templates called by the block.
+	literal []string
+	// built reports whether some keys are built by actions, e.g. {{ .metadata.name }}=value,
+	// so they are only known once the block is rendered.
+	built bool
+}
+
+// keyState is the position of the scanner in the rendered output: within a key, or within a value.
+type keyState struct {
+	inKey bool
+	key   string
+	// built reports whether the current key contains the output of an action.
+	built bool
+}
+
+// merge returns the state after either of the branches ending in the states. If the branches end
+// in different states, the key is conservatively considered as built by an action.
+func (s keyState) merge(other keyState) keyState {
+	if s == other {
+		return s
+	}
+
+	return keyState{inKey: s.inKey || other.inKey, built: true}
+}
+
+// keyScanner walks the template, following the output format parsed by unmarshalAnnotations.
+type keyScanner struct {
+	tpl     *template.Template
+	keys    blockKeys
+	calling []string
+}
+
+// scanKeys returns the keys of the block parsed into the template.
+func scanKeys(tpl *template.Template) blockKeys {
+	if tpl == nil || tpl.Tree == nil {
+		return blockKeys{}
+	}
+
+	s := &keyScanner{tpl: tpl}
+	s.walk(tpl.Tree.Root, keyState{inKey: true})
+
+	return s.keys
+}
+
+func (s *keyScanner) walk(node parse.Node, state keyState) keyState {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return state
+		}
+		for _, child := range n.Nodes {
+			state = s.walk(child, state)
+		}
+		return state
+
+	case *parse.TextNode:
+		return s.text(string(n.Text), state)
+
+	case *parse.ActionNode:
+		// Declarations, e.g. {{ $name := .metadata.name }}, do not write anything.
+		if n.Pipe != nil && len(n.Pipe.Decl) > 0 {
+			return state
+		}
+		if state.inKey {
+			state.built = true
+		}
+		return state
+
+	case *parse.TemplateNode:
+		tpl := s.tpl.Lookup(n.Name)
+		if tpl == nil || tpl.Tree == nil || slices.Contains(s.calling, n.Name) {
+			if state.inKey {
+				state.built = true
+			}
+			return state
+		}
+
+		s.calling = append(s.calling, n.Name)
+		defer func() { s.calling = s.calling[:len(s.calling)-1] }()
+
+		return s.walk(tpl.Tree.Root, state)
+
+	case *parse.IfNode:
+		return s.branch(&n.BranchNode, state, false)
+
+	case *parse.RangeNode:
+		return s.branch(&n.BranchNode, state, true)
+
+	case *parse.WithNode:
+		return s.branch(&n.BranchNode, state, false)
+
+	default:
+		return state
+	}
+}
+
+// branch walks both branches of the node. A loop body may be executed any number of times,
+// so it is walked twice, the second time from any state the first iteration can end in.
+func (s *keyScanner) branch(n *parse.BranchNode, state keyState, loop bool) keyState {
+	end := s.walk(n.List, state)
+	if loop {
+		end = state.merge(s.walk(n.List, state.merge(end)))
+	}
+
+	return end.merge(s.walk(n.ElseList, state))
+}
+
+// text scans the text like unmarshalAnnotations parses it: pairs are separated by commas or newlines,
+// and the key ends at the first equal sign of the pair.
+func (s *keyScanner) text(text string, state keyState) keyState {
+	for _, c := range text {
+		switch {
+		case c == ',' || c == '\n':
+			state = keyState{inKey: true}
+
+		case c == '=' && state.inKey:
+			if state.built {
+				s.keys.built = true
+			} else if key := strings.TrimSpace(state.key); key != "" && !slices.Contains(s.keys.literal, key) {
+				s.keys.literal = append(s.keys.literal, key)
+			}
+			state = keyState{}
+
+		case state.inKey:
+			state.key += string(c)
+		}
+	}
+
+	return state
+}
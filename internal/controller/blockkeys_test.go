@@ -0,0 +1,79 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanKeys(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		text     string
+		expected blockKeys
+	}{
+		"literal keys": {
+			text:     "team=a,\nowner={{ .metadata.name }}",
+			expected: blockKeys{literal: []string{"team", "owner"}},
+		},
+		"keys in branches": {
+			text:     `{{ if .spec }}team=a{{ else }}owner=b{{ end }}{{ range .items }},item=c{{ end }}`,
+			expected: blockKeys{literal: []string{"team", "owner", "item"}},
+		},
+		"declaration": {
+			text:     `{{ $name := .metadata.name }}team={{ $name }}`,
+			expected: blockKeys{literal: []string{"team"}},
+		},
+		"key built by action": {
+			text:     `{{ if .metadata.labels.app }}{{ "sidecar.istio.io/inject" }}=false{{ end }}`,
+			expected: blockKeys{built: true},
+		},
+		"key prefix": {
+			text:     `team=a,sidecar.istio.io/{{ .name }}=true`,
+			expected: blockKeys{literal: []string{"team"}, built: true},
+		},
+		"key from shared template": {
+			text:     `{{ template "mesh" . }},team=a`,
+			expected: blockKeys{literal: []string{"sidecar.istio.io/inject", "team"}},
+		},
+		"key after loop": {
+			text:     `{{ range .items }}{{ . }}{{ end }}=a`,
+			expected: blockKeys{built: true},
+		},
+		"branches ending within different keys": {
+			text:     `{{ if .spec }}team{{ end }}=a`,
+			expected: blockKeys{built: true},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			shared, err := template.New("mesh").Parse("sidecar.istio.io/inject=true")
+			require.NoError(t, err)
+
+			tpl, err := shared.New("").Parse(tc.text)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, scanKeys(tpl))
+		})
+	}
+}
@@ -0,0 +1,237 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// templateCacheSize bounds the number of namespaces kept in the cache.
+const templateCacheSize = 4096
+
+// TemplateCache keeps parsed namespace templates, keyed by the Namespace UID and resourceVersion.
+// It is meant to be shared by all reconcilers, since a single namespace change
+// triggers reconciliation of every object of every observed type in that namespace.
+type TemplateCache struct {
+	mu      sync.Mutex
+	entries map[types.UID]*templateEntry
+}
+
+// NewTemplateCache creates an empty TemplateCache.
+func NewTemplateCache() *TemplateCache {
+	return &TemplateCache{
+		entries: make(map[types.UID]*templateEntry),
+	}
+}
+
+// templateEntry is a parsed namespace template. Static templates, which never reference
+// the object data, are rendered once and the result is shared by all objects. Templates
+// that timed out are not executed again, until the namespace changes.
+type templateEntry struct {
+	resourceVersion string
+	tpl             *template.Template
+	static          bool
+	keys            blockKeys
+
+	mu       sync.Mutex
+	rendered *string
+	timeout  error
+}
+
+// get returns the cached template for the namespace, or parses and caches a new one.
+// Namespaces without UID, e.g. those not yet persisted, are never cached.
+func (c *TemplateCache) get(
+	ns *corev1.Namespace,
+	parse func() (*template.Template, error),
+) (*templateEntry, error) {
+	if c == nil || ns.UID == "" {
+		return newTemplateEntry(ns.ResourceVersion, parse)
+	}
+
+	c.mu.Lock()
+	entry, ok := c.entries[ns.UID]
+	c.mu.Unlock()
+
+	if ok && entry.resourceVersion == ns.ResourceVersion {
+		templateCacheCounter.With(prometheus.Labels{"result": "hit"}).Inc()
+		return entry, nil
+	}
+
+	templateCacheCounter.With(prometheus.Labels{"result": "miss"}).Inc()
+
+	entry, err := newTemplateEntry(ns.ResourceVersion, parse)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[ns.UID]; !ok && len(c.entries) >= templateCacheSize {
+		clear(c.entries)
+	}
+	c.entries[ns.UID] = entry
+
+	return entry, nil
+}
+
+// newTemplateEntry parses the template and checks whether it is static.
+func newTemplateEntry(resourceVersion string, parse func() (*template.Template, error)) (*templateEntry, error) {
+	tpl, err := parse()
+	if err != nil {
+		return nil, err
+	}
+
+	return &templateEntry{
+		resourceVersion: resourceVersion,
+		tpl:             tpl,
+		static:          tpl.Tree == nil || isStaticNode(tpl.Tree.Root),
+		keys:            scanKeys(tpl),
+	}, nil
+}
+
+// render returns the shared result of a static template, rendering it on the first call.
+// Failed renders are not cached, so they are retried by the next caller, except timeouts.
+func (e *templateEntry) render(execute func() (string, error)) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.rendered != nil {
+		return *e.rendered, nil
+	}
+	if e.timeout != nil {
+		return "", e.timeout
+	}
+
+	out, err := execute()
+	if err != nil {
+		if isTimeout(err) {
+			e.timeout = err
+		}
+		return "", err
+	}
+
+	e.rendered = &out
+
+	return out, nil
+}
+
+// execute executes a dynamic template for an object. Once an execution timed out,
+// the timeout is returned for every object, without executing the template again.
+func (e *templateEntry) execute(execute func() (string, error)) (string, error) {
+	e.mu.Lock()
+	timeout := e.timeout
+	e.mu.Unlock()
+
+	if timeout != nil {
+		return "", timeout
+	}
+
+	out, err := execute()
+	if err != nil && isTimeout(err) {
+		e.mu.Lock()
+		e.timeout = err
+		e.mu.Unlock()
+	}
+
+	return out, err
+}
+
+// isStaticNode reports whether the node never reads the data passed to the template.
+// The check is conservative: any use of dot or of the root variable makes the node dynamic.
+func isStaticNode(node parse.Node) bool {
+	switch n := node.(type) {
+	case nil:
+		return true
+
+	case *parse.ListNode:
+		if n == nil {
+			return true
+		}
+		for _, child := range n.Nodes {
+			if !isStaticNode(child) {
+				return false
+			}
+		}
+		return true
+
+	case *parse.ActionNode:
+		return isStaticNode(n.Pipe)
+
+	case *parse.IfNode:
+		return isStaticBranch(&n.BranchNode)
+
+	case *parse.RangeNode:
+		return isStaticBranch(&n.BranchNode)
+
+	case *parse.WithNode:
+		return isStaticBranch(&n.BranchNode)
+
+	case *parse.TemplateNode:
+		// Templates called without a pipeline receive nil data.
+		return n.Pipe == nil || isStaticNode(n.Pipe)
+
+	case *parse.PipeNode:
+		if n == nil {
+			return true
+		}
+		for _, cmd := range n.Cmds {
+			if !isStaticNode(cmd) {
+				return false
+			}
+		}
+		return true
+
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if !isStaticNode(arg) {
+				return false
+			}
+		}
+		return true
+
+	case *parse.ChainNode:
+		return isStaticNode(n.Node)
+
+	case *parse.VariableNode:
+		// $ is the data passed to the template, other variables are checked where declared.
+		return len(n.Ident) > 0 && n.Ident[0] != "$"
+
+	case *parse.DotNode, *parse.FieldNode:
+		return false
+
+	case *parse.TextNode, *parse.CommentNode, *parse.BoolNode, *parse.NumberNode,
+		*parse.StringNode, *parse.NilNode, *parse.IdentifierNode,
+		*parse.BreakNode, *parse.ContinueNode:
+		return true
+
+	default:
+		return false
+	}
+}
+
+// isStaticBranch reports whether the condition and both branches of the node are static.
+func isStaticBranch(n *parse.BranchNode) bool {
+	return isStaticNode(n.Pipe) && isStaticNode(n.List) && isStaticNode(n.ElseList)
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsStaticNode(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		text     string
+		expected bool
+	}{
+		"plain text": {
+			text:     "key1=value1,\nkey2=value2",
+			expected: true,
+		},
+		"constant pipeline": {
+			text:     `key1={{ "value1" | printf "%s-suffix" }}`,
+			expected: true,
+		},
+		"static condition": {
+			text:     `{{ if true }}key1=value1{{ else }}key2=value2{{ end }}`,
+			expected: true,
+		},
+		"local variable": {
+			text:     `{{ $v := "value1" }}key1={{ $v }}`,
+			expected: true,
+		},
+		"template without data": {
+			text:     `{{ define "t" }}key1=value1{{ end }}{{ template "t" }}`,
+			expected: true,
+		},
+		"field": {
+			text:     "key1={{ .metadata.name }}",
+			expected: false,
+		},
+		"dot": {
+			text:     "key1={{ . }}",
+			expected: false,
+		},
+		"root variable": {
+			text:     "key1={{ $.metadata.name }}",
+			expected: false,
+		},
+		"template with data": {
+			text:     `{{ define "t" }}key1=value1{{ end }}{{ template "t" . }}`,
+			expected: false,
+		},
+		"field in condition": {
+			text:     `{{ if .spec }}key1=value1{{ end }}`,
+			expected: false,
+		},
+		"field in else branch": {
+			text:     `{{ if true }}key1=value1{{ else }}{{ .spec }}{{ end }}`,
+			expected: false,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tpl, err := template.New("").Parse(tc.text)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, isStaticNode(tpl.Tree.Root))
+		})
+	}
+}
+
+func TestTemplateCache(t *testing.T) {
+	t.Parallel()
+
+	cache := NewTemplateCache()
+
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "test-namespace",
+			UID:             "test-uid",
+			ResourceVersion: "1",
+		},
+	}
+
+	parses := 0
+	parse := func() (*template.Template, error) {
+		parses++
+		return template.New("").Parse("key1=value1")
+	}
+
+	first, err := cache.get(ns, parse)
+	require.NoError(t, err)
+	assert.True(t, first.static)
+
+	second, err := cache.get(ns, parse)
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+	assert.Equal(t, 1, parses)
+
+	ns.ResourceVersion = "2"
+
+	third, err := cache.get(ns, parse)
+	require.NoError(t, err)
+	assert.NotSame(t, first, third)
+	assert.Equal(t, 2, parses)
+
+	renders := 0
+	for range 3 {
+		out, err := third.render(func() (string, error) {
+			renders++
+			return "key1=value1", nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "key1=value1", out)
+	}
+	assert.Equal(t, 1, renders)
+}
+
+func TestTemplateCacheWithoutUID(t *testing.T) {
+	t.Parallel()
+
+	cache := NewTemplateCache()
+
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "test-namespace",
+			ResourceVersion: "1",
+		},
+	}
+
+	parse := func() (*template.Template, error) {
+		return template.New("").Parse("key1={{ .metadata.name }}")
+	}
+
+	first, err := cache.get(ns, parse)
+	require.NoError(t, err)
+	assert.False(t, first.static)
+
+	second, err := cache.get(ns, parse)
+	require.NoError(t, err)
+	assert.NotSame(t, first, second)
+}
+
+func TestTemplateEntryTimeout(t *testing.T) {
+	t.Parallel()
+
+	for name, static := range map[string]bool{"static": true, "dynamic": false} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			entry := &templateEntry{static: static}
+
+			executions := 0
+			execute := func() (string, error) {
+				executions++
+				return "", &TemplateLimitError{Limit: limitExecutionTimeout}
+			}
+
+			for range 3 {
+				var err error
+				if static {
+					_, err = entry.render(execute)
+				} else {
+					_, err = entry.execute(execute)
+				}
+				assert.True(t, isTimeout(err))
+			}
+			assert.Equal(t, 1, executions)
+		})
+	}
+}
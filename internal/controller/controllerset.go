@@ -0,0 +1,793 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/restmapper"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/anza-labs/scribe/api/v1alpha1"
+	"github.com/anza-labs/scribe/internal/config"
+)
+
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch
+
+// ControllerSet runs an UnstructuredReconciler controller for every configured type.
+// The configuration can be replaced at runtime: controllers for new types are started,
+// controllers for removed types are stopped, and controllers for changed types are restarted.
+// Controllers are started only once the set itself is started by the Manager, i.e. after
+// the leader election is won. If the configuration contains include rules, the types selected
+// by them are resolved through the discovery API. Types configured without a version are
+// resolved to the preferred version served by the cluster. Types that are not served yet,
+// e.g. because their CRD is not installed, are pending until they appear. Types are resolved
+// again periodically, and whenever a CRD changes. The permissions required by every type are
+// checked whenever types are resolved; types missing permissions are not started.
+type ControllerSet struct {
+	mgr       ctrl.Manager
+	discovery discovery.DiscoveryInterface
+	mapper    meta.ResettableRESTMapper
+	review    reviewFunc
+	recorder  record.EventRecorder
+	warnings  *OptInWarnings
+
+	// reconfiguring serializes the changes of the types, which call the API server, so mu is only
+	// held while the state is read or changed, and never blocks the readers, e.g. the webhooks.
+	reconfiguring sync.Mutex
+	granted       map[schema.GroupVersionResource]time.Time
+
+	mu         sync.Mutex
+	ctx        context.Context
+	cfg        *config.Config
+	global     string
+	cache      *TemplateCache
+	discovered []config.Type
+	namespaces []string
+	keys       AnnotationKeys
+	optIn      bool
+	object     runtime.Object
+	pending    []schema.GroupVersionKind
+	failed     map[schema.GroupVersionKind]string
+	forbidden  map[schema.GroupVersionKind]string
+	onChange   []func()
+	versions   map[schema.GroupKind]string
+	running    map[schema.GroupVersionKind]*runningController
+}
+
+// runningController is a controller started by the ControllerSet.
+type runningController struct {
+	// hash identifies the configuration the controller was started with.
+	hash   string
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// ControllerSetOption configures optional behavior of the ControllerSet.
+type ControllerSetOption func(*ControllerSet)
+
+// WithWatchNamespaces restricts the controllers to the given namespaces. The namespaces are
+// polled instead of watched, and CRDs are not watched, so no cluster-wide permissions are needed.
+func WithWatchNamespaces(namespaces []string) ControllerSetOption {
+	return func(s *ControllerSet) {
+		s.namespaces = namespaces
+	}
+}
+
+// WithAnnotationKeys sets the annotation keys used by the controllers, e.g. to run several
+// instances of scribe, each processing the namespace blocks addressed to its class.
+func WithAnnotationKeys(keys AnnotationKeys) ControllerSetOption {
+	return func(s *ControllerSet) {
+		s.keys = keys
+	}
+}
+
+// WithRequiredOptIn makes the controllers ignore the namespaces without the opt-in label.
+func WithRequiredOptIn() ControllerSetOption {
+	return func(s *ControllerSet) {
+		s.optIn = true
+	}
+}
+
+// WithEventObject emits the events about the types, e.g. missing permissions, on the given object,
+// typically the Pod of the manager.
+func WithEventObject(obj runtime.Object) ControllerSetOption {
+	return func(s *ControllerSet) {
+		s.object = obj
+	}
+}
+
+// NewControllerSet creates a ControllerSet with the initial configuration.
+func NewControllerSet(mgr ctrl.Manager, cfg *config.Config, opts ...ControllerSetOption) *ControllerSet {
+	s := &ControllerSet{
+		mgr:      mgr,
+		cfg:      cfg,
+		versions: make(map[schema.GroupKind]string),
+		running:  make(map[schema.GroupVersionKind]*runningController),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Start implements manager.Runnable. It starts the controllers for the current configuration,
+// refreshes the discovered types periodically, and stops all controllers when the context is done.
+func (s *ControllerSet) Start(ctx context.Context) error {
+	if s.discovery == nil {
+		dc, err := discovery.NewDiscoveryClientForConfig(s.mgr.GetConfig())
+		if err != nil {
+			return fmt.Errorf("unable to create discovery client: %w", err)
+		}
+		s.discovery = dc
+	}
+
+	if s.mapper == nil {
+		s.mapper = restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(s.discovery))
+	}
+
+	if s.review == nil {
+		s.review = selfSubjectAccessReview(s.mgr.GetClient())
+	}
+
+	if s.recorder == nil && s.object != nil {
+		s.recorder = s.mgr.GetEventRecorderFor("scribe")
+	}
+
+	if s.warnings == nil && s.optIn {
+		s.warnings = NewOptInWarnings(s.mgr.GetEventRecorderFor("scribe"))
+	}
+
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+
+	err := s.reconfigure()
+	if err != nil {
+		return err
+	}
+
+	// Without cluster-wide permissions, new CRDs are noticed by the periodic refresh only.
+	var crds <-chan struct{}
+	if len(s.namespaces) == 0 {
+		crds, err = s.watchCRDs(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	for {
+		s.mu.Lock()
+		interval := s.cfg.GetDiscoveryInterval()
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			s.reconfiguring.Lock()
+			defer s.reconfiguring.Unlock()
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			for gvk := range s.running {
+				s.stop(gvk, false)
+			}
+
+			return nil
+
+		case <-crds:
+			s.refresh()
+
+		case <-time.After(interval):
+			s.refresh()
+		}
+	}
+}
+
+// Update replaces the configuration. If the set is already started, the running
+// controllers are changed to match the new configuration.
+func (s *ControllerSet) Update(cfg *config.Config) error {
+	s.mu.Lock()
+	s.cfg = cfg
+	started := s.ctx != nil && s.ctx.Err() == nil
+	s.mu.Unlock()
+
+	if !started {
+		return nil
+	}
+
+	return s.reconfigure()
+}
+
+// reconfigure resolves the types of the current configuration, reviews their permissions, and starts
+// or stops controllers to match them. Discovery and reviews call the API server, so they are made
+// without holding the lock; concurrent reconfigurations are serialized instead.
+func (s *ControllerSet) reconfigure() error {
+	s.reconfiguring.Lock()
+	defer s.reconfiguring.Unlock()
+
+	s.mu.Lock()
+	cfg := s.cfg
+	s.mu.Unlock()
+
+	s.discover(cfg)
+
+	types, errs, pendingChanged := s.resolve(mergeTypes(cfg.Types, s.discovered))
+	res := &resolution{
+		types:          types,
+		errs:           errs,
+		pendingChanged: pendingChanged,
+		reviews:        s.reviewPermissions(types),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.apply(cfg, res)
+}
+
+// resolution is the state of the configured types, resolved before the configuration is applied.
+type resolution struct {
+	// types are the types served by the cluster.
+	types []config.Type
+	// errs are the errors resolving the types.
+	errs []error
+	// pendingChanged reports whether the pending types changed.
+	pendingChanged bool
+	// reviews are the results of the permission reviews of the types.
+	reviews map[schema.GroupVersionKind]error
+}
+
+// watchCRDs returns a channel receiving a value whenever a CRD is created, changed or deleted.
+// Events are coalesced, so a burst of changes results in a single refresh.
+func (s *ControllerSet) watchCRDs(ctx context.Context) (<-chan struct{}, error) {
+	crd := &metav1.PartialObjectMetadata{}
+	crd.SetGroupVersionKind(apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinition"))
+
+	informer, err := s.mgr.GetCache().GetInformer(ctx, crd)
+	if err != nil {
+		return nil, fmt.Errorf("unable to watch custom resource definitions: %w", err)
+	}
+
+	changed := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+
+	if _, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    func(any) { notify() },
+		UpdateFunc: func(any, any) { notify() },
+		DeleteFunc: func(any) { notify() },
+	}); err != nil {
+		return nil, fmt.Errorf("unable to watch custom resource definitions: %w", err)
+	}
+
+	return changed, nil
+}
+
+// refresh resolves the include rules and the types again, and starts or stops controllers
+// for the types that appeared, disappeared or changed their version since.
+func (s *ControllerSet) refresh() {
+	s.reconfiguring.Lock()
+	s.mapper.Reset()
+	s.reconfiguring.Unlock()
+
+	if err := s.reconfigure(); err != nil {
+		log.FromContext(s.ctx).Error(err, "Unable to apply resolved types")
+	}
+}
+
+// Pending returns the kinds that are configured, but not served by the cluster.
+// The version is empty for types configured without one.
+func (s *ControllerSet) Pending() []schema.GroupVersionKind {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.pending)
+}
+
+// ReadyzCheck implements healthz.Checker. It fails while any configured type is pending.
+func (s *ControllerSet) ReadyzCheck(_ *http.Request) error {
+	pending := s.Pending()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(pending))
+	for _, gvk := range pending {
+		names = append(names, gvk.GroupKind().String())
+	}
+
+	return fmt.Errorf("pending types: %s", strings.Join(names, ", "))
+}
+
+// PermissionsCheck implements healthz.Checker. It fails while any type is missing the permissions
+// required to reconcile it, and lists every such type with the missing verbs.
+func (s *ControllerSet) PermissionsCheck(_ *http.Request) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.forbidden) == 0 {
+		return nil
+	}
+
+	messages := make([]string, 0, len(s.forbidden))
+	for gvk, msg := range s.forbidden {
+		messages = append(messages, fmt.Sprintf("%s: %s", gvk.GroupKind(), msg))
+	}
+	slices.Sort(messages)
+
+	return errors.New(strings.Join(messages, "; "))
+}
+
+// discover resolves the include and exclude rules to types. If discovery fails,
+// the previously discovered types are kept. It is called while reconfiguring.
+func (s *ControllerSet) discover(cfg *config.Config) {
+	if len(cfg.Include) == 0 {
+		s.discovered = nil
+		return
+	}
+
+	log := log.FromContext(s.ctx)
+
+	discovered, err := discoverTypes(s.discovery, cfg.Include, cfg.Exclude)
+	if err != nil {
+		log.Error(err, "Unable to discover types")
+		if discovered == nil {
+			return
+		}
+		discovered = keepFailedGroups(discovered, s.discovered, err)
+	}
+
+	s.discovered = discovered
+}
+
+// apply starts and stops controllers to match the configuration and its resolved types. Errors
+// related to a single type, e.g. missing permissions or a controller failing to start, are logged
+// and reported as failed types; they do not prevent other changes, and are not returned. Only errors
+// affecting the whole configuration are returned.
+func (s *ControllerSet) apply(cfg *config.Config, res *resolution) error {
+	log := log.FromContext(s.ctx)
+
+	templates, err := cfg.SharedTemplates()
+	if err != nil {
+		return err
+	}
+
+	global, err := configHash(cfg.Templates, cfg.Limits, cfg.Filter, cfg.Policy)
+	if err != nil {
+		return err
+	}
+
+	// Parsed templates depend on the shared templates, so the cache is replaced with them.
+	if global != s.global || s.cache == nil {
+		s.global = global
+		s.cache = NewTemplateCache()
+	}
+
+	previousFailed := s.failed
+
+	all, forbidden := s.authorize(res.types, res.reviews)
+	errs := append(slices.Clone(res.errs), forbidden...)
+
+	desired := make(map[schema.GroupVersionKind]string, len(all))
+	types := make(map[schema.GroupVersionKind]config.Type, len(all))
+	for _, t := range all {
+		hash, err := configHash(t, global)
+		if err != nil {
+			return err
+		}
+
+		desired[t.GroupVersionKind()] = hash
+		types[t.GroupVersionKind()] = t
+	}
+
+	running := make(map[schema.GroupVersionKind]string, len(s.running))
+	for gvk, rc := range s.running {
+		running[gvk] = rc.hash
+	}
+
+	start, stop := diffControllers(running, desired)
+
+	for _, gvk := range stop {
+		_, keep := desired[gvk]
+		log.Info("Stopping controller", "group_version_kind", gvk)
+		s.stop(gvk, !keep)
+	}
+
+	for _, gvk := range start {
+		log.Info("Starting controller", "group_version_kind", gvk)
+		if err := s.start(cfg, types[gvk], templates, desired[gvk]); err != nil {
+			errs = append(errs, &typeError{gvk: gvk, err: err})
+		}
+	}
+
+	s.failed = make(map[schema.GroupVersionKind]string)
+	for _, err := range errs {
+		var te *typeError
+		if errors.As(err, &te) {
+			s.failed[te.gvk] = te.Error()
+			// Missing permissions are logged when they are reviewed.
+			if previousFailed[te.gvk] != te.Error() && !errors.As(te.err, new(*permissionError)) {
+				log.Error(te.err, "Unable to reconcile type", "group_version_kind", te.gvk)
+			}
+		}
+	}
+
+	changed := len(start) > 0 || len(stop) > 0 ||
+		res.pendingChanged || !maps.Equal(previousFailed, s.failed)
+	if changed {
+		for _, fn := range s.onChange {
+			fn()
+		}
+	}
+
+	return nil
+}
+
+// Config returns the current configuration.
+func (s *ControllerSet) Config() *config.Config {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.cfg
+}
+
+// OnChange registers a function called whenever the state of the types changed. The function
+// is called with the set locked, so it must not block, nor call other methods of the set.
+func (s *ControllerSet) OnChange(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.onChange = append(s.onChange, fn)
+}
+
+// TypeStatuses returns the state of every type: active types have a running controller,
+// pending types are not served by the cluster, and failed types could not be started.
+func (s *ControllerSet) TypeStatuses() []v1alpha1.TypeStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var statuses []v1alpha1.TypeStatus
+
+	add := func(gvk schema.GroupVersionKind, state v1alpha1.TypeState, msg string) {
+		statuses = append(statuses, v1alpha1.TypeStatus{
+			Group:   gvk.Group,
+			Version: gvk.Version,
+			Kind:    gvk.Kind,
+			State:   state,
+			Message: msg,
+		})
+	}
+
+	for gvk := range s.running {
+		add(gvk, v1alpha1.TypeActive, "")
+	}
+	for _, gvk := range s.pending {
+		add(gvk, v1alpha1.TypePending, "Type is not served by the cluster")
+	}
+	for gvk, msg := range s.failed {
+		add(gvk, v1alpha1.TypeFailed, msg)
+	}
+
+	slices.SortFunc(statuses, func(a, b v1alpha1.TypeStatus) int {
+		return strings.Compare(a.Group+"/"+a.Kind, b.Group+"/"+b.Kind)
+	})
+
+	return statuses
+}
+
+// resolve checks that the types are served by the cluster, and sets the preferred version
+// on the unversioned types. The selected version is logged whenever it changes, and the types
+// that are not served are recorded as pending. It reports whether the pending types changed.
+// It is called while reconfiguring, without holding the lock, as the mapper may call the API server.
+func (s *ControllerSet) resolve(types []config.Type) ([]config.Type, []error, bool) {
+	if s.mapper == nil {
+		return types, nil, false
+	}
+
+	log := log.FromContext(s.ctx)
+
+	resolved, pending, errs := resolveTypes(s.mapper, types)
+
+	unversioned := make(map[schema.GroupKind]bool)
+	for _, t := range types {
+		if t.Unversioned() {
+			unversioned[t.GroupVersionKind().GroupKind()] = true
+		}
+	}
+
+	for _, t := range resolved {
+		gvk := t.GroupVersionKind()
+		if !unversioned[gvk.GroupKind()] || s.versions[gvk.GroupKind()] == gvk.Version {
+			continue
+		}
+
+		log.Info("Selected preferred version", "group_kind", gvk.GroupKind(), "version", gvk.Version)
+		s.versions[gvk.GroupKind()] = gvk.Version
+	}
+
+	pendingTypesGauge.Reset()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := s.pending
+	s.pending = nil
+	for _, t := range pending {
+		gvk := t.GroupVersionKind()
+		s.pending = append(s.pending, gvk)
+		pendingTypesGauge.With(prometheus.Labels{"group_kind": gvk.GroupKind().String()}).Set(1)
+
+		if !slices.Contains(previous, gvk) {
+			log.Info("Type is not served by the cluster, waiting for it to appear", "group_kind", gvk.GroupKind())
+		}
+	}
+
+	return resolved, errs, !slices.Equal(previous, s.pending)
+}
+
+// reviewPermissions reviews the permissions required to reconcile the types, and returns the result
+// for every type whose resource is known. It calls the API server for every type, and every watched
+// namespace, so it is called without holding the lock. Granted permissions are trusted for a while,
+// so bursts of CRD changes do not review every type again; missing ones are reviewed every time.
+func (s *ControllerSet) reviewPermissions(types []config.Type) map[schema.GroupVersionKind]error {
+	if s.review == nil || s.mapper == nil {
+		return nil
+	}
+
+	if s.granted == nil {
+		s.granted = make(map[schema.GroupVersionResource]time.Time)
+	}
+
+	reviews := make(map[schema.GroupVersionKind]error, len(types))
+
+	for _, t := range types {
+		gvk := t.GroupVersionKind()
+
+		mapping, err := s.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			// Starting the controller fails too, and reports the error.
+			continue
+		}
+
+		if granted, ok := s.granted[mapping.Resource]; ok && time.Since(granted) < permissionsTTL {
+			reviews[gvk] = nil
+			continue
+		}
+
+		err = checkPermissions(s.ctx, s.review, mapping.Resource, s.namespaces)
+		if err == nil {
+			s.granted[mapping.Resource] = time.Now()
+		} else {
+			delete(s.granted, mapping.Resource)
+		}
+		reviews[gvk] = err
+	}
+
+	return reviews
+}
+
+// authorize returns the permitted types, together with an error for every type missing permissions,
+// according to the reviews. Types without a review are permitted. Missing permissions are reported by
+// the readiness check and the metric, and logged, with an event, whenever they change.
+func (s *ControllerSet) authorize(
+	types []config.Type,
+	reviews map[schema.GroupVersionKind]error,
+) ([]config.Type, []error) {
+	log := log.FromContext(s.ctx)
+
+	previous := s.forbidden
+	s.forbidden = make(map[schema.GroupVersionKind]string)
+	missingPermissionsGauge.Reset()
+
+	var permitted []config.Type
+	var errs []error
+
+	for _, t := range types {
+		gvk := t.GroupVersionKind()
+		err := reviews[gvk]
+
+		var permErr *permissionError
+		if errors.As(err, &permErr) {
+			msg := permErr.Error()
+			s.forbidden[gvk] = msg
+			errs = append(errs, &typeError{gvk: gvk, err: permErr})
+
+			for _, verb := range permErr.verbs {
+				missingPermissionsGauge.With(prometheus.Labels{
+					"group_kind": gvk.GroupKind().String(),
+					"verb":       verb,
+				}).Set(1)
+			}
+
+			if previous[gvk] != msg {
+				log.Info("Missing permissions, the type is not reconciled", "group_version_kind", gvk, "reason", msg)
+				s.event(corev1.EventTypeWarning, MissingPermissions, fmt.Sprintf("%s: %s", gvk.GroupKind(), msg))
+			}
+			continue
+		}
+
+		if err != nil {
+			// Without a review, the type is reconciled anyway, and missing permissions surface as errors.
+			log.Error(err, "Unable to check permissions", "group_version_kind", gvk)
+		} else if _, ok := previous[gvk]; ok {
+			log.Info("Permissions granted", "group_version_kind", gvk)
+		}
+
+		permitted = append(permitted, t)
+	}
+
+	return permitted, errs
+}
+
+// event emits an event on the configured object, if any.
+func (s *ControllerSet) event(eventType, reason, msg string) {
+	if s.recorder == nil || s.object == nil {
+		return
+	}
+
+	s.recorder.Event(s.object, eventType, reason, msg)
+}
+
+// start creates and starts the controller for the given type of the configuration being applied.
+func (s *ControllerSet) start(cfg *config.Config, t config.Type, templates *template.Template, hash string) error {
+	gvk := t.GroupVersionKind()
+
+	if _, err := s.mgr.GetRESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+		return fmt.Errorf("unable to find %s: %w", gvk, err)
+	}
+
+	filters := []config.Filter{cfg.Filter, t.Filter}
+	if len(s.namespaces) > 0 {
+		filters = append(filters, config.Filter{Namespaces: s.namespaces})
+	}
+
+	filter, err := NewObjectFilter(filters...)
+	if err != nil {
+		return fmt.Errorf("invalid filter for %s: %w", gvk, err)
+	}
+
+	r := &UnstructuredReconciler{
+		Client:    s.mgr.GetClient(),
+		Scheme:    s.mgr.GetScheme(),
+		Recorder:  s.mgr.GetEventRecorderFor(gvk.String()),
+		Templates: templates,
+		Limits:    cfg.Limits,
+		Fields:    t.Fields,
+		Cache:     s.cache,
+		Filter:    filter,
+		Policy:    cfg.Policy,
+		Options:   t.Controller,
+		Keys:      s.keys,
+
+		RequireOptIn:    s.optIn,
+		WatchNamespaces: s.namespaces,
+		OptInWarnings:   s.warnings,
+	}
+
+	c, err := r.NewController(s.mgr, gvk)
+	if err != nil {
+		return fmt.Errorf("unable to create controller for %s: %w", gvk, err)
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		if err := c.Start(ctx); err != nil {
+			log.FromContext(ctx).Error(err, "Controller stopped with error", "group_version_kind", gvk)
+		}
+	}()
+
+	s.running[gvk] = &runningController{
+		hash:   hash,
+		cancel: cancel,
+		done:   done,
+	}
+
+	return nil
+}
+
+// stop stops the controller for the given kind and waits for it to finish.
+// If removeInformer is set, the informer for the kind is removed from the cache as well.
+func (s *ControllerSet) stop(gvk schema.GroupVersionKind, removeInformer bool) {
+	rc, ok := s.running[gvk]
+	if !ok {
+		return
+	}
+
+	rc.cancel()
+	<-rc.done
+	delete(s.running, gvk)
+
+	if removeInformer {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(gvk)
+
+		if err := s.mgr.GetCache().RemoveInformer(s.ctx, u); err != nil {
+			log.FromContext(s.ctx).Error(err, "Unable to remove informer", "group_version_kind", gvk)
+		}
+	}
+}
+
+// diffControllers returns the kinds that must be started and stopped to move from the running
+// controllers to the desired ones. Both maps contain the configuration hash of every kind;
+// kinds with a changed hash are restarted.
+func diffControllers(
+	running, desired map[schema.GroupVersionKind]string,
+) (start, stop []schema.GroupVersionKind) {
+	for gvk, hash := range running {
+		if desiredHash, ok := desired[gvk]; !ok || desiredHash != hash {
+			stop = append(stop, gvk)
+		}
+	}
+
+	for gvk, hash := range desired {
+		if runningHash, ok := running[gvk]; !ok || runningHash != hash {
+			start = append(start, gvk)
+		}
+	}
+
+	compare := func(a, b schema.GroupVersionKind) int {
+		return strings.Compare(a.String(), b.String())
+	}
+
+	slices.SortFunc(start, compare)
+	slices.SortFunc(stop, compare)
+
+	return start, stop
+}
+
+// configHash returns a string identifying the given configuration values.
+func configHash(values ...any) (string, error) {
+	raw, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	return string(raw), nil
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestDiffControllers(t *testing.T) {
+	t.Parallel()
+
+	pod := corev1.SchemeGroupVersion.WithKind("Pod")
+	deployment := appsv1.SchemeGroupVersion.WithKind("Deployment")
+	statefulSet := appsv1.SchemeGroupVersion.WithKind("StatefulSet")
+
+	for name, tc := range map[string]struct {
+		running       map[schema.GroupVersionKind]string
+		desired       map[schema.GroupVersionKind]string
+		expectedStart []schema.GroupVersionKind
+		expectedStop  []schema.GroupVersionKind
+	}{
+		"initial start": {
+			running:       map[schema.GroupVersionKind]string{},
+			desired:       map[schema.GroupVersionKind]string{pod: "a", deployment: "a"},
+			expectedStart: []schema.GroupVersionKind{pod, deployment},
+		},
+		"no changes": {
+			running: map[schema.GroupVersionKind]string{pod: "a", deployment: "a"},
+			desired: map[schema.GroupVersionKind]string{pod: "a", deployment: "a"},
+		},
+		"added and removed types": {
+			running:       map[schema.GroupVersionKind]string{pod: "a", deployment: "a"},
+			desired:       map[schema.GroupVersionKind]string{pod: "a", statefulSet: "a"},
+			expectedStart: []schema.GroupVersionKind{statefulSet},
+			expectedStop:  []schema.GroupVersionKind{deployment},
+		},
+		"changed type": {
+			running:       map[schema.GroupVersionKind]string{pod: "a", deployment: "a"},
+			desired:       map[schema.GroupVersionKind]string{pod: "b", deployment: "a"},
+			expectedStart: []schema.GroupVersionKind{pod},
+			expectedStop:  []schema.GroupVersionKind{pod},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			start, stop := diffControllers(tc.running, tc.desired)
+			assert.Equal(t, tc.expectedStart, start)
+			assert.Equal(t, tc.expectedStop, stop)
+		})
+	}
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+
+	"github.com/anza-labs/scribe/internal/config"
+)
+
+// requiredVerbs are the verbs a resource must support to be reconciled.
+var requiredVerbs = []string{"get", "list", "watch", "update"}
+
+// ignoredKinds are never selected by discovery, as annotating them makes no sense
+// and they are created at a high rate.
+var ignoredKinds = []schema.GroupKind{
+	{Group: "", Kind: "Event"},
+	{Group: "events.k8s.io", Kind: "Event"},
+}
+
+// mergeTypes returns the explicitly configured types, followed by the discovered types.
+// Explicit types take precedence over discovered ones of the same group and kind.
+func mergeTypes(explicit, discovered []config.Type) []config.Type {
+	types := slices.Clone(explicit)
+
+	seen := make(map[schema.GroupKind]bool, len(types))
+	for _, t := range types {
+		seen[t.GroupVersionKind().GroupKind()] = true
+	}
+
+	for _, t := range discovered {
+		gk := t.GroupVersionKind().GroupKind()
+		if seen[gk] {
+			continue
+		}
+
+		seen[gk] = true
+		types = append(types, t)
+	}
+
+	return types
+}
+
+// keepFailedGroups adds the previously discovered types from the group versions
+// that failed discovery, so a temporarily unavailable API does not stop their controllers.
+func keepFailedGroups(discovered, previous []config.Type, err error) []config.Type {
+	var failed *discovery.ErrGroupDiscoveryFailed
+	if !errors.As(err, &failed) {
+		return discovered
+	}
+
+	for _, t := range previous {
+		if _, ok := failed.Groups[t.GroupVersionKind().GroupVersion()]; ok {
+			discovered = append(discovered, t)
+		}
+	}
+
+	return discovered
+}
+
+// discoverTypes lists the preferred versions of the namespaced resources, which support
+// all required verbs, and are selected by include and not by exclude.
+func discoverTypes(
+	dc discovery.DiscoveryInterface,
+	include, exclude []config.TypeSelector,
+) ([]config.Type, error) {
+	lists, err := discovery.ServerPreferredNamespacedResources(dc)
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, fmt.Errorf("unable to discover resources: %w", err)
+	}
+	if err != nil {
+		err = fmt.Errorf("unable to discover some resources: %w", err)
+	}
+
+	var types []config.Type
+
+	for _, list := range lists {
+		gv, parseErr := schema.ParseGroupVersion(list.GroupVersion)
+		if parseErr != nil {
+			continue
+		}
+
+		for _, res := range list.APIResources {
+			// Subresources, e.g. deployments/scale, are not objects on their own.
+			if strings.Contains(res.Name, "/") {
+				continue
+			}
+
+			if !hasVerbs(res.Verbs, requiredVerbs) {
+				continue
+			}
+
+			gk := schema.GroupKind{Group: gv.Group, Kind: res.Kind}
+			if slices.Contains(ignoredKinds, gk) {
+				continue
+			}
+
+			if !config.MatchesAny(include, gk) || config.MatchesAny(exclude, gk) {
+				continue
+			}
+
+			types = append(types, config.Type{
+				APIVersion: gv.String(),
+				Kind:       res.Kind,
+			})
+		}
+	}
+
+	slices.SortFunc(types, func(a, b config.Type) int {
+		return strings.Compare(a.GroupVersionKind().String(), b.GroupVersionKind().String())
+	})
+
+	return types, err
+}
+
+// hasVerbs reports whether all required verbs are supported.
+func hasVerbs(verbs, required []string) bool {
+	for _, v := range required {
+		if !slices.Contains(verbs, v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// resolveTypes checks that the types are served by the cluster, and sets the preferred version
+// on the unversioned ones. Types whose kind is not served, e.g. because the CRD is not installed
+// yet, are returned as pending. Types that cannot be resolved for other reasons are skipped,
+// and a typeError is returned for each of them.
+func resolveTypes(mapper meta.RESTMapper, types []config.Type) (resolved, pending []config.Type, errs []error) {
+	for _, t := range types {
+		gvk := t.GroupVersionKind()
+
+		var versions []string
+		if !t.Unversioned() {
+			versions = append(versions, gvk.Version)
+		}
+
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), versions...)
+		if meta.IsNoMatchError(err) {
+			pending = append(pending, t)
+			continue
+		}
+		if err != nil {
+			errs = append(errs, &typeError{gvk: gvk, err: fmt.Errorf("unable to find %s: %w", gvk.GroupKind(), err)})
+			continue
+		}
+
+		if t.Unversioned() {
+			t.APIVersion = mapping.GroupVersionKind.GroupVersion().String()
+			t.Group = ""
+		}
+		resolved = append(resolved, t)
+	}
+
+	return resolved, pending, errs
+}
+
+// typeError is an error related to a single configured type.
+type typeError struct {
+	gvk schema.GroupVersionKind
+	err error
+}
+
+func (e *typeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *typeError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/anza-labs/scribe/internal/config"
+)
+
+func TestDiscoverTypes(t *testing.T) {
+	t.Parallel()
+
+	verbs := metav1.Verbs{"get", "list", "watch", "create", "update", "patch", "delete"}
+
+	resources := []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "pods", Kind: "Pod", Namespaced: true, Verbs: verbs},
+				{Name: "pods/status", Kind: "Pod", Namespaced: true, Verbs: metav1.Verbs{"get", "update"}},
+				{Name: "events", Kind: "Event", Namespaced: true, Verbs: verbs},
+				{Name: "namespaces", Kind: "Namespace", Namespaced: false, Verbs: verbs},
+				{Name: "bindings", Kind: "Binding", Namespaced: true, Verbs: metav1.Verbs{"create"}},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", Kind: "Deployment", Namespaced: true, Verbs: verbs},
+				{Name: "statefulsets", Kind: "StatefulSet", Namespaced: true, Verbs: verbs},
+			},
+		},
+		{
+			GroupVersion: "foo.example.com/v1alpha1",
+			APIResources: []metav1.APIResource{
+				{Name: "bars", Kind: "Bar", Namespaced: true, Verbs: verbs},
+			},
+		},
+	}
+
+	for name, tc := range map[string]struct {
+		include  []config.TypeSelector
+		exclude  []config.TypeSelector
+		expected []config.Type
+	}{
+		"group": {
+			include: []config.TypeSelector{{Group: "apps"}},
+			expected: []config.Type{
+				{APIVersion: "apps/v1", Kind: "Deployment"},
+				{APIVersion: "apps/v1", Kind: "StatefulSet"},
+			},
+		},
+		"wildcard group": {
+			include: []config.TypeSelector{{Group: "*.example.com", Kind: "*"}},
+			expected: []config.Type{
+				{APIVersion: "foo.example.com/v1alpha1", Kind: "Bar"},
+			},
+		},
+		"core group skips subresources, events and cluster-scoped resources": {
+			include: []config.TypeSelector{{Group: "core"}},
+			expected: []config.Type{
+				{APIVersion: "v1", Kind: "Pod"},
+			},
+		},
+		"exclude": {
+			include: []config.TypeSelector{{Group: "apps"}},
+			exclude: []config.TypeSelector{{Kind: "StatefulSet"}},
+			expected: []config.Type{
+				{APIVersion: "apps/v1", Kind: "Deployment"},
+			},
+		},
+		"nothing selected": {
+			include: []config.TypeSelector{{Group: "batch"}},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dc := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: resources}}
+
+			types, err := discoverTypes(dc, tc.include, tc.exclude)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, types)
+		})
+	}
+}
+
+func TestMergeTypes(t *testing.T) {
+	t.Parallel()
+
+	explicit := []config.Type{
+		{APIVersion: "apps/v1", Kind: "Deployment", Fields: config.Fields{Allow: []string{"metadata"}}},
+	}
+	discovered := []config.Type{
+		{APIVersion: "apps/v1beta1", Kind: "Deployment"},
+		{APIVersion: "apps/v1", Kind: "StatefulSet"},
+	}
+
+	assert.Equal(t, []config.Type{
+		{APIVersion: "apps/v1", Kind: "Deployment", Fields: config.Fields{Allow: []string{"metadata"}}},
+		{APIVersion: "apps/v1", Kind: "StatefulSet"},
+	}, mergeTypes(explicit, discovered))
+}
+
+func TestKeepFailedGroups(t *testing.T) {
+	t.Parallel()
+
+	previous := []config.Type{
+		{APIVersion: "apps/v1", Kind: "Deployment"},
+		{APIVersion: "foo.example.com/v1", Kind: "Bar"},
+	}
+	discovered := []config.Type{
+		{APIVersion: "apps/v1", Kind: "Deployment"},
+	}
+	err := &discovery.ErrGroupDiscoveryFailed{
+		Groups: map[schema.GroupVersion]error{
+			{Group: "foo.example.com", Version: "v1"}: errors.New("unavailable"),
+		},
+	}
+
+	assert.Equal(t, previous, keepFailedGroups(discovered, previous, err))
+	assert.Equal(t, discovered, keepFailedGroups(discovered, previous, errors.New("other")))
+}
+
+func TestResolveTypes(t *testing.T) {
+	t.Parallel()
+
+	// The order of the group versions is the order of preference.
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{
+		{Group: "foo.example.com", Version: "v1"},
+		{Group: "foo.example.com", Version: "v1beta1"},
+		{Version: "v1"},
+	})
+	mapper.Add(schema.GroupVersionKind{Group: "foo.example.com", Version: "v1beta1", Kind: "Bar"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "foo.example.com", Version: "v1", Kind: "Bar"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Pod"}, meta.RESTScopeNamespace)
+
+	for name, tc := range map[string]struct {
+		types           []config.Type
+		expected        []config.Type
+		expectedPending []config.Type
+		expectError     bool
+	}{
+		"versioned type": {
+			types:    []config.Type{{APIVersion: "foo.example.com/v1beta1", Kind: "Bar"}},
+			expected: []config.Type{{APIVersion: "foo.example.com/v1beta1", Kind: "Bar"}},
+		},
+		"group and kind": {
+			types:    []config.Type{{Group: "foo.example.com", Kind: "Bar"}},
+			expected: []config.Type{{APIVersion: "foo.example.com/v1", Kind: "Bar"}},
+		},
+		"core group": {
+			types:    []config.Type{{Kind: "Pod"}},
+			expected: []config.Type{{APIVersion: "v1", Kind: "Pod"}},
+		},
+		"missing kind": {
+			types:           []config.Type{{Kind: "Pod"}, {Group: "foo.example.com", Kind: "Baz"}},
+			expected:        []config.Type{{APIVersion: "v1", Kind: "Pod"}},
+			expectedPending: []config.Type{{Group: "foo.example.com", Kind: "Baz"}},
+		},
+		"missing version": {
+			types:           []config.Type{{APIVersion: "foo.example.com/v2", Kind: "Bar"}},
+			expectedPending: []config.Type{{APIVersion: "foo.example.com/v2", Kind: "Bar"}},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			resolved, pending, errs := resolveTypes(mapper, tc.types)
+			if tc.expectError {
+				assert.NotEmpty(t, errs)
+			} else {
+				assert.Empty(t, errs)
+			}
+			assert.Equal(t, tc.expected, resolved)
+			assert.Equal(t, tc.expectedPending, pending)
+		})
+	}
+}
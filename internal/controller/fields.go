@@ -0,0 +1,75 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/anza-labs/scribe/internal/config"
+)
+
+// alwaysAllowedFields are kept even if they are not on the allow list,
+// because they identify the type of the object.
+var alwaysAllowedFields = []string{"apiVersion", "kind"}
+
+// filterFields returns a copy of the object, containing only the fields that templates may read.
+// If the allow list is set, only the listed fields are copied; then the denied fields are removed.
+func filterFields(object map[string]any, fields config.Fields) (map[string]any, error) {
+	var result map[string]any
+
+	if len(fields.Allow) == 0 {
+		result = runtime.DeepCopyJSON(object)
+	} else {
+		result = make(map[string]any)
+
+		for _, field := range alwaysAllowedFields {
+			if v, ok := object[field]; ok {
+				result[field] = v
+			}
+		}
+
+		for _, p := range fields.Allow {
+			path, err := config.ParseFieldPath(p)
+			if err != nil {
+				return nil, fmt.Errorf("invalid allowed field: %w", err)
+			}
+
+			v, found, err := unstructured.NestedFieldNoCopy(object, path...)
+			if err != nil || !found {
+				continue
+			}
+
+			if err := unstructured.SetNestedField(result, runtime.DeepCopyJSONValue(v), path...); err != nil {
+				return nil, fmt.Errorf("failed to copy allowed field %q: %w", p, err)
+			}
+		}
+	}
+
+	for _, p := range fields.DeniedFields() {
+		path, err := config.ParseFieldPath(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid denied field: %w", err)
+		}
+
+		unstructured.RemoveNestedField(result, path...)
+	}
+
+	return result, nil
+}
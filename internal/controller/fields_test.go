@@ -0,0 +1,121 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anza-labs/scribe/internal/config"
+)
+
+func TestFilterFields(t *testing.T) {
+	t.Parallel()
+
+	newObject := func() map[string]any {
+		return map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata": map[string]any{
+				"name": "test",
+				"annotations": map[string]any{
+					"kubectl.kubernetes.io/last-applied-configuration": "{}",
+					"team": "platform",
+				},
+				"managedFields": []any{map[string]any{"manager": "kubectl"}},
+			},
+			"data": map[string]any{"password": "c2VjcmV0"},
+		}
+	}
+
+	for name, tc := range map[string]struct {
+		fields   config.Fields
+		expected map[string]any
+	}{
+		"default deny list": {
+			fields: config.Fields{},
+			expected: map[string]any{
+				"apiVersion": "v1",
+				"kind":       "Secret",
+				"metadata": map[string]any{
+					"name": "test",
+					"annotations": map[string]any{
+						"team": "platform",
+					},
+				},
+			},
+		},
+		"allow list": {
+			fields: config.Fields{
+				Allow: []string{"metadata.name", "metadata.missing"},
+			},
+			expected: map[string]any{
+				"apiVersion": "v1",
+				"kind":       "Secret",
+				"metadata": map[string]any{
+					"name": "test",
+				},
+			},
+		},
+		"explicit deny list": {
+			fields: config.Fields{
+				Deny: []string{"metadata.annotations[team]", "data"},
+			},
+			expected: map[string]any{
+				"apiVersion": "v1",
+				"kind":       "Secret",
+				"metadata": map[string]any{
+					"name": "test",
+					"annotations": map[string]any{
+						"kubectl.kubernetes.io/last-applied-configuration": "{}",
+					},
+					"managedFields": []any{map[string]any{"manager": "kubectl"}},
+				},
+			},
+		},
+		"allow and deny lists": {
+			fields: config.Fields{
+				Allow: []string{"metadata"},
+				Deny:  []string{"metadata.managedFields"},
+			},
+			expected: map[string]any{
+				"apiVersion": "v1",
+				"kind":       "Secret",
+				"metadata": map[string]any{
+					"name": "test",
+					"annotations": map[string]any{
+						"kubectl.kubernetes.io/last-applied-configuration": "{}",
+						"team": "platform",
+					},
+				},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			object := newObject()
+
+			result, err := filterFields(object, tc.fields)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+			assert.Equal(t, newObject(), object, "original object must not be modified")
+		})
+	}
+}
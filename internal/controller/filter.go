@@ -0,0 +1,186 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/anza-labs/scribe/internal/config"
+)
+
+// ObjectFilter selects the objects managed by a reconciler. It combines the global filter
+// with the filter of the type, so an object must be selected by both. A nil filter selects everything.
+type ObjectFilter struct {
+	filters            []config.Filter
+	objectSelectors    []labels.Selector
+	namespaceSelectors []labels.Selector
+}
+
+// NewObjectFilter compiles the given filters into an ObjectFilter.
+func NewObjectFilter(filters ...config.Filter) (*ObjectFilter, error) {
+	f := &ObjectFilter{filters: filters}
+
+	for _, filter := range filters {
+		objectSelector, err := filter.ObjectSelector.Selector()
+		if err != nil {
+			return nil, err
+		}
+
+		namespaceSelector, err := filter.NamespaceSelector.Selector()
+		if err != nil {
+			return nil, err
+		}
+
+		f.objectSelectors = append(f.objectSelectors, objectSelector)
+		f.namespaceSelectors = append(f.namespaceSelectors, namespaceSelector)
+	}
+
+	return f, nil
+}
+
+// IncludesNamespaceName reports whether objects in the namespace with the given name can be selected.
+func (f *ObjectFilter) IncludesNamespaceName(name string) bool {
+	if f == nil {
+		return true
+	}
+
+	for _, filter := range f.filters {
+		if !filter.IncludesNamespace(name) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IncludesNamespace reports whether objects in the namespace can be selected.
+func (f *ObjectFilter) IncludesNamespace(ns *corev1.Namespace) bool {
+	if f == nil {
+		return true
+	}
+
+	if !f.IncludesNamespaceName(ns.Name) {
+		return false
+	}
+
+	for _, selector := range f.namespaceSelectors {
+		if !selector.Matches(labels.Set(ns.Labels)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MatchesLabels reports whether the object labels are selected.
+func (f *ObjectFilter) MatchesLabels(objLabels map[string]string) bool {
+	if f == nil {
+		return true
+	}
+
+	for _, selector := range f.objectSelectors {
+		if !selector.Matches(labels.Set(objLabels)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ListOptions returns the options restricting a list call to the selected objects in the namespace.
+func (f *ObjectFilter) ListOptions(namespace string) *client.ListOptions {
+	opts := &client.ListOptions{Namespace: namespace}
+	if f == nil {
+		return opts
+	}
+
+	selector := labels.NewSelector()
+	for _, s := range f.objectSelectors {
+		reqs, _ := s.Requirements()
+		selector = selector.Add(reqs...)
+	}
+	opts.LabelSelector = selector
+
+	return opts
+}
+
+// hasNamespaceSelector reports whether the namespace labels have to be checked.
+func (f *ObjectFilter) hasNamespaceSelector() bool {
+	if f == nil {
+		return false
+	}
+
+	for _, selector := range f.namespaceSelectors {
+		if !selector.Empty() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// objectPredicate filters the events of the managed objects. The namespace of the object
+// is read from the cache only if a namespace selector is set.
+func objectPredicate(c client.Reader, f *ObjectFilter) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		if !f.MatchesLabels(obj.GetLabels()) || !f.IncludesNamespaceName(obj.GetNamespace()) {
+			return false
+		}
+
+		if !f.hasNamespaceSelector() {
+			return true
+		}
+
+		ctx := context.Background()
+
+		ns := &corev1.Namespace{}
+		if err := c.Get(ctx, types.NamespacedName{Name: obj.GetNamespace()}, ns); err != nil {
+			log.FromContext(ctx).V(1).Error(err, "Unable to get namespace to filter event", "namespace", obj.GetNamespace())
+			return false
+		}
+
+		return f.IncludesNamespace(ns)
+	})
+}
+
+// namespacePredicate filters the events of namespaces whose objects are not managed. Updates of
+// namespaces that stop being selected pass too, so the keys applied to their objects are removed.
+func namespacePredicate(f *ObjectFilter) predicate.Predicate {
+	includes := func(obj client.Object) bool {
+		ns, ok := obj.(*corev1.Namespace)
+		if !ok {
+			return f.IncludesNamespaceName(obj.GetName())
+		}
+
+		return f.IncludesNamespace(ns)
+	}
+
+	p := predicate.NewPredicateFuncs(includes)
+	p.UpdateFunc = func(e event.UpdateEvent) bool {
+		return includes(e.ObjectOld) || includes(e.ObjectNew)
+	}
+
+	return p
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/anza-labs/scribe/internal/config"
+)
+
+func TestObjectPredicate(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	managed := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "managed", Labels: map[string]string{"team": "a"}}}
+	other := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other"}}
+	system := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system", Labels: map[string]string{"team": "a"}}}
+
+	helm := map[string]string{"app.kubernetes.io/managed-by": "helm"}
+
+	for name, tc := range map[string]struct {
+		filters  []config.Filter
+		object   *corev1.Pod
+		expected bool
+	}{
+		"no filters": {
+			object:   newPod("other", nil),
+			expected: true,
+		},
+		"object selector": {
+			filters:  []config.Filter{{ObjectSelector: &config.LabelSelector{MatchLabels: helm}}},
+			object:   newPod("other", helm),
+			expected: true,
+		},
+		"object selector not matching": {
+			filters: []config.Filter{{ObjectSelector: &config.LabelSelector{MatchLabels: helm}}},
+			object:  newPod("other", nil),
+		},
+		"namespace selector": {
+			filters:  []config.Filter{{NamespaceSelector: &config.LabelSelector{MatchLabels: map[string]string{"team": "a"}}}},
+			object:   newPod("managed", nil),
+			expected: true,
+		},
+		"namespace selector not matching": {
+			filters: []config.Filter{{NamespaceSelector: &config.LabelSelector{MatchLabels: map[string]string{"team": "a"}}}},
+			object:  newPod("other", nil),
+		},
+		"excluded namespace": {
+			filters: []config.Filter{
+				{NamespaceSelector: &config.LabelSelector{MatchLabels: map[string]string{"team": "a"}}},
+				{ExcludedNamespaces: []string{"kube-system"}},
+			},
+			object: newPod("kube-system", nil),
+		},
+		"included namespaces": {
+			filters:  []config.Filter{{Namespaces: []string{"man*"}}},
+			object:   newPod("managed", nil),
+			expected: true,
+		},
+		"not included namespace": {
+			filters: []config.Filter{{Namespaces: []string{"man*"}}},
+			object:  newPod("other", nil),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(managed, other, system).Build()
+
+			filter, err := NewObjectFilter(tc.filters...)
+			require.NoError(t, err)
+
+			p := objectPredicate(c, filter)
+			assert.Equal(t, tc.expected, p.Create(event.CreateEvent{Object: tc.object}))
+		})
+	}
+}
+
+func TestNamespacePredicate(t *testing.T) {
+	t.Parallel()
+
+	filter, err := NewObjectFilter(
+		config.Filter{NamespaceSelector: &config.LabelSelector{MatchLabels: map[string]string{"team": "a"}}},
+		config.Filter{ExcludedNamespaces: []string{"kube-system"}},
+	)
+	require.NoError(t, err)
+
+	p := namespacePredicate(filter)
+
+	assert.True(t, p.Create(event.CreateEvent{Object: &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "managed", Labels: map[string]string{"team": "a"}},
+	}}))
+	assert.False(t, p.Create(event.CreateEvent{Object: &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "other"},
+	}}))
+	assert.False(t, p.Create(event.CreateEvent{Object: &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "kube-system", Labels: map[string]string{"team": "a"}},
+	}}))
+
+	selected := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "managed", Labels: map[string]string{"team": "a"}}}
+	unselected := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "managed", Labels: map[string]string{"team": "b"}}}
+
+	assert.True(t, p.Update(event.UpdateEvent{ObjectOld: selected, ObjectNew: selected}), "still selected")
+	assert.True(t, p.Update(event.UpdateEvent{ObjectOld: unselected, ObjectNew: selected}), "selected")
+	assert.True(t, p.Update(event.UpdateEvent{ObjectOld: selected, ObjectNew: unselected}), "no longer selected")
+	assert.False(t, p.Update(event.UpdateEvent{ObjectOld: unselected, ObjectNew: unselected}), "never selected")
+}
+
+func newPod(namespace string, labels map[string]string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "pod", Labels: labels}}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// DefaultDomain is the domain of the annotations read and written by scribe.
+const DefaultDomain = "scribe.anza-labs.dev"
+
+const (
+	annotations            = DefaultDomain + "/annotations"
+	lastAppliedAnnotations = DefaultDomain + "/last-applied-annotations"
+	rules                  = DefaultDomain + "/rules"
+	enabled                = DefaultDomain + "/enabled"
+)
+
+// DefaultAnnotationKeys are the annotation keys used by an instance without a class.
+var DefaultAnnotationKeys = AnnotationKeys{
+	Annotations: annotations,
+	LastApplied: lastAppliedAnnotations,
+	Rules:       rules,
+	Enabled:     enabled,
+}
+
+// AnnotationKeys are the annotation keys used by a scribe instance: the namespace block,
+// the rules, the opt-in label, and the bookkeeping of the keys applied to an object.
+type AnnotationKeys struct {
+	// Annotations is the namespace annotation containing the block propagated to objects.
+	Annotations string
+	// LastApplied is the object annotation listing the keys applied by the instance.
+	LastApplied string
+	// Rules is the namespace annotation containing the conditions of the block.
+	Rules string
+	// Enabled is the namespace label opting the namespace in, when an opt-in is required.
+	Enabled string
+}
+
+// NewAnnotationKeys returns the annotation keys in the given domain. If a class is set, it is
+// prepended to the domain, e.g. "tenant.scribe.anza-labs.dev/annotations", so an instance only
+// processes the blocks addressed to its class, and keeps its bookkeeping in its own annotation.
+func NewAnnotationKeys(domain, class string) (AnnotationKeys, error) {
+	if domain == "" {
+		domain = DefaultDomain
+	}
+
+	if class != "" {
+		if errs := validation.IsDNS1123Label(class); len(errs) > 0 {
+			return AnnotationKeys{}, fmt.Errorf("invalid class %q: %s", class, strings.Join(errs, ", "))
+		}
+		domain = class + "." + domain
+	}
+
+	if errs := validation.IsDNS1123Subdomain(domain); len(errs) > 0 {
+		return AnnotationKeys{}, fmt.Errorf("invalid domain %q: %s", domain, strings.Join(errs, ", "))
+	}
+
+	return AnnotationKeys{
+		Annotations: domain + "/annotations",
+		LastApplied: domain + "/last-applied-annotations",
+		Rules:       domain + "/rules",
+		Enabled:     domain + "/enabled",
+	}, nil
+}
+
+// withDefaults returns the default keys if none are set.
+func (k AnnotationKeys) withDefaults() AnnotationKeys {
+	if k == (AnnotationKeys{}) {
+		return DefaultAnnotationKeys
+	}
+	return k
+}
+
+// ManagedKeys returns the keys recorded as applied by the instance in the annotations of an object.
+func (k AnnotationKeys) ManagedKeys(objAnnotations map[string]string) []string {
+	lastApplied := unmarshalAnnotations(objAnnotations[k.withDefaults().LastApplied])
+
+	keys := make([]string, 0, len(lastApplied))
+	for key := range lastApplied {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNewAnnotationKeys(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		domain      string
+		class       string
+		expected    AnnotationKeys
+		expectError bool
+	}{
+		"default": {
+			expected: DefaultAnnotationKeys,
+		},
+		"domain": {
+			domain: "example.com",
+			expected: AnnotationKeys{
+				Annotations: "example.com/annotations",
+				LastApplied: "example.com/last-applied-annotations",
+				Rules:       "example.com/rules",
+				Enabled:     "example.com/enabled",
+			},
+		},
+		"class": {
+			class: "tenant",
+			expected: AnnotationKeys{
+				Annotations: "tenant.scribe.anza-labs.dev/annotations",
+				LastApplied: "tenant.scribe.anza-labs.dev/last-applied-annotations",
+				Rules:       "tenant.scribe.anza-labs.dev/rules",
+				Enabled:     "tenant.scribe.anza-labs.dev/enabled",
+			},
+		},
+		"invalid domain": {
+			domain:      "Example.com",
+			expectError: true,
+		},
+		"invalid class": {
+			class:       "tenant.a",
+			expectError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			keys, err := NewAnnotationKeys(tc.domain, tc.class)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, keys)
+		})
+	}
+}
+
+func TestNamespaceScopeClass(t *testing.T) {
+	t.Parallel()
+
+	keys, err := NewAnnotationKeys("", "tenant")
+	require.NoError(t, err)
+
+	c := fake.NewClientBuilder().WithObjects(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-namespace",
+			Namespace: "test-namespace",
+			Annotations: map[string]string{
+				annotations:      "platform=value",
+				keys.Annotations: "tenant=value",
+			},
+		},
+	}).Build()
+
+	nss := NewNamespaceScope(c, "test-namespace", WithKeys(keys))
+
+	result, err := nss.UpdateAnnotations(context.Background(), map[string]string{
+		lastAppliedAnnotations: "platform=value",
+		"platform":             "value",
+	}, map[string]any{})
+	require.NoError(t, err)
+
+	// The bookkeeping of the default instance is left untouched.
+	assert.Equal(t, map[string]string{
+		lastAppliedAnnotations: "platform=value",
+		"platform":             "value",
+		"tenant":               "value",
+		keys.LastApplied:       "tenant=value",
+	}, result)
+}
+
+func TestManagedKeys(t *testing.T) {
+	t.Parallel()
+
+	keys, err := NewAnnotationKeys("", "tenant")
+	require.NoError(t, err)
+
+	objAnnotations := map[string]string{
+		lastAppliedAnnotations: "platform=value",
+		keys.LastApplied:       "tenant=value,\nteam=a",
+	}
+
+	assert.Equal(t, []string{"platform"}, AnnotationKeys{}.ManagedKeys(objAnnotations))
+	assert.Equal(t, []string{"team", "tenant"}, keys.ManagedKeys(objAnnotations))
+	assert.Empty(t, keys.ManagedKeys(nil))
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"text/template"
+
+	"github.com/anza-labs/scribe/internal/config"
+)
+
+const TemplateLimitExceeded = "TemplateLimitExceeded"
+
+const (
+	limitExecutionTimeout  = "execution_timeout"
+	limitMaxOutputSize     = "max_output_size"
+	limitMaxTemplateLength = "max_template_length"
+)
+
+// maxAbandonedExecutions bounds the number of timed out executions still running. Above it,
+// templates are not executed at all, so templates that never write, e.g. ranges over huge
+// integers, cannot exhaust the CPU of the manager by being executed again and again.
+const maxAbandonedExecutions = 8
+
+// abandonedExecutions counts the timed out executions that are still running.
+var abandonedExecutions atomic.Int32
+
+// The states of an execution, see executeTemplate.
+const (
+	executionRunning int32 = iota
+	executionFinished
+	executionAbandoned
+)
+
+// TemplateLimitError is returned when a template exceeds one of the configured limits.
+type TemplateLimitError struct {
+	// Limit is the name of the exceeded limit.
+	Limit string
+	msg   string
+}
+
+// Error returns the description of the exceeded limit.
+func (e *TemplateLimitError) Error() string {
+	return fmt.Sprintf("template limit exceeded: %s", e.msg)
+}
+
+// limitedWriter is a buffer that refuses writes above the maximum size,
+// or after the execution context is done.
+type limitedWriter struct {
+	ctx context.Context
+	buf bytes.Buffer
+	max int
+}
+
+// Write implements io.Writer.
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	if w.buf.Len()+len(p) > w.max {
+		return 0, &TemplateLimitError{
+			Limit: limitMaxOutputSize,
+			msg:   fmt.Sprintf("output is larger than %d bytes", w.max),
+		}
+	}
+
+	return w.buf.Write(p)
+}
+
+// checkTemplateLength verifies that the template text is within the configured length.
+func checkTemplateLength(text string, limits config.Limits) error {
+	if len(text) > limits.MaxTemplateLength {
+		return &TemplateLimitError{
+			Limit: limitMaxTemplateLength,
+			msg:   fmt.Sprintf("template is longer than %d bytes", limits.MaxTemplateLength),
+		}
+	}
+
+	return nil
+}
+
+// executeTemplate executes the template with the output size and execution time bounded by limits.
+// Templates cannot be interrupted, so on timeout the execution is abandoned, and it stops
+// as soon as it attempts to write any further output. Templates that never write keep running,
+// so no template is executed while too many abandoned executions are still running.
+func executeTemplate(
+	ctx context.Context,
+	tpl *template.Template,
+	data any,
+	limits config.Limits,
+) (string, error) {
+	if abandonedExecutions.Load() >= maxAbandonedExecutions {
+		return "", &TemplateLimitError{
+			Limit: limitExecutionTimeout,
+			msg:   fmt.Sprintf("%d timed out executions are still running", maxAbandonedExecutions),
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, limits.ExecutionTimeout)
+	defer cancel()
+
+	w := &limitedWriter{ctx: ctx, max: limits.MaxOutputSize}
+	done := make(chan error, 1)
+
+	var state atomic.Int32
+	go func() {
+		err := tpl.Execute(w, data)
+		if !state.CompareAndSwap(executionRunning, executionFinished) {
+			abandonedExecutions.Add(-1)
+		}
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			// Errors returned by the writer are passed through by text/template as-is.
+			var limitErr *TemplateLimitError
+			if errors.As(err, &limitErr) {
+				return "", limitErr
+			}
+			return "", fmt.Errorf("failed to execute template: %w", err)
+		}
+		return w.buf.String(), nil
+
+	case <-ctx.Done():
+		if state.CompareAndSwap(executionRunning, executionAbandoned) {
+			abandonedExecutions.Add(1)
+		}
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", ctx.Err()
+		}
+		return "", &TemplateLimitError{
+			Limit: limitExecutionTimeout,
+			msg:   fmt.Sprintf("execution took longer than %s", limits.ExecutionTimeout),
+		}
+	}
+}
+
+// isTimeout reports whether the error is a timeout of the template execution.
+func isTimeout(err error) bool {
+	var limitErr *TemplateLimitError
+	return errors.As(err, &limitErr) && limitErr.Limit == limitExecutionTimeout
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anza-labs/scribe/internal/config"
+)
+
+func TestExecuteTemplate(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		text          string
+		data          any
+		limits        config.Limits
+		expected      string
+		expectedLimit string
+	}{
+		"within limits": {
+			text:     "key={{ .name }}",
+			data:     map[string]any{"name": "value"},
+			expected: "key=value",
+		},
+		"output too large": {
+			text:          `{{ range .items }}key=value,{{ end }}`,
+			data:          map[string]any{"items": make([]int, 100)},
+			limits:        config.Limits{MaxOutputSize: 64},
+			expectedLimit: limitMaxOutputSize,
+		},
+		"execution timeout": {
+			text:          `{{ range .items }}{{ range $.items }}.{{ end }}{{ end }}`,
+			data:          map[string]any{"items": make([]int, 100000)},
+			limits:        config.Limits{ExecutionTimeout: 10 * time.Millisecond, MaxOutputSize: 1 << 30},
+			expectedLimit: limitExecutionTimeout,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tpl, err := template.New("").Parse(tc.text)
+			require.NoError(t, err)
+
+			result, err := executeTemplate(context.Background(), tpl, tc.data, tc.limits.WithDefaults())
+			if tc.expectedLimit != "" {
+				var limitErr *TemplateLimitError
+				require.ErrorAs(t, err, &limitErr)
+				assert.Equal(t, tc.expectedLimit, limitErr.Limit)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestCheckTemplateLength(t *testing.T) {
+	t.Parallel()
+
+	limits := config.Limits{MaxTemplateLength: 8}.WithDefaults()
+
+	assert.NoError(t, checkTemplateLength("foo=bar", limits))
+
+	var limitErr *TemplateLimitError
+	require.ErrorAs(t, checkTemplateLength(strings.Repeat("a", 9), limits), &limitErr)
+	assert.Equal(t, limitMaxTemplateLength, limitErr.Limit)
+}
+
+// TestExecuteTemplateAbandoned is not parallel, as it counts the goroutines of the process.
+func TestExecuteTemplateAbandoned(t *testing.T) {
+	release := make(chan struct{})
+	tpl, err := template.New("").Funcs(template.FuncMap{
+		// wait never writes, like a range over a huge integer, until the test releases it.
+		"wait": func() string {
+			<-release
+			return ""
+		},
+	}).Parse("{{ wait }}")
+	require.NoError(t, err)
+
+	limits := config.Limits{ExecutionTimeout: time.Millisecond}.WithDefaults()
+	before := runtime.NumGoroutine()
+
+	for range 2 * maxAbandonedExecutions {
+		_, err := executeTemplate(context.Background(), tpl, nil, limits)
+
+		var limitErr *TemplateLimitError
+		require.ErrorAs(t, err, &limitErr)
+		assert.Equal(t, limitExecutionTimeout, limitErr.Limit)
+	}
+
+	assert.LessOrEqual(t, runtime.NumGoroutine()-before, maxAbandonedExecutions)
+
+	// The abandoned executions stop once released. Polled in place, as assert.Eventually
+	// starts goroutines itself.
+	close(release)
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); {
+		if abandonedExecutions.Load() == 0 && runtime.NumGoroutine() <= before {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	assert.Zero(t, abandonedExecutions.Load())
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+}
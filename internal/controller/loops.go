@@ -0,0 +1,155 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const UpdateLoopDetected = "UpdateLoopDetected"
+
+// loopThreshold is the number of consecutive reconciles changing the managed keys,
+// without any change to the inputs, after which an update loop is reported.
+const loopThreshold = 3
+
+// loopDetector detects objects whose managed annotations change on every reconcile,
+// even though the inputs of the template did not change. This happens with
+// non-deterministic templates, e.g. using time or random values, where every
+// write triggers the watch again, and the object would be updated forever.
+type loopDetector struct {
+	mu     sync.Mutex
+	states map[types.NamespacedName]*loopState
+}
+
+// loopState is the last observed state of a single object.
+type loopState struct {
+	inputs  string
+	managed map[string]string
+	changes int
+}
+
+func newLoopDetector() *loopDetector {
+	return &loopDetector{
+		states: make(map[types.NamespacedName]*loopState),
+	}
+}
+
+// observe records the managed annotations computed for the object from the given inputs.
+// It returns the offending keys if the object is in an update loop, in which case
+// the annotations must not be written.
+func (d *loopDetector) observe(key types.NamespacedName, inputs string, managed map[string]string) []string {
+	if d == nil {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	state, ok := d.states[key]
+	if !ok || state.inputs != inputs {
+		d.states[key] = &loopState{inputs: inputs, managed: managed}
+		return nil
+	}
+
+	changed := changedKeys(state.managed, managed)
+	if len(changed) == 0 {
+		state.changes = 0
+		return nil
+	}
+
+	state.changes++
+	if state.changes < loopThreshold {
+		state.managed = managed
+		return nil
+	}
+
+	// The managed annotations are not updated, so the loop is reported
+	// until the inputs change.
+	return changed
+}
+
+// forget removes the state of the object, e.g. after it was deleted.
+func (d *loopDetector) forget(key types.NamespacedName) {
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.states, key)
+}
+
+// changedKeys returns the sorted keys whose values differ between the two maps.
+func changedKeys(previous, current map[string]string) []string {
+	var keys []string
+
+	for k, v := range current {
+		if pv, ok := previous[k]; !ok || pv != v {
+			keys = append(keys, k)
+		}
+	}
+	for k := range previous {
+		if _, ok := current[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
+
+// loopInputs returns the fingerprint of everything the managed annotations are computed from:
+// the namespace version and the object, without the managed annotations themselves and
+// without the fields that change on every write, including the last-applied bookkeeping.
+func loopInputs(ns *corev1.Namespace, object map[string]any, managed map[string]string, lastApplied string) (string, error) {
+	obj := runtime.DeepCopyJSON(object)
+
+	unstructured.RemoveNestedField(obj, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(obj, "metadata", "generation")
+	unstructured.RemoveNestedField(obj, "metadata", "managedFields")
+	unstructured.RemoveNestedField(obj, "metadata", "annotations", lastApplied)
+	for k := range managed {
+		unstructured.RemoveNestedField(obj, "metadata", "annotations", k)
+	}
+
+	// Maps are marshaled with sorted keys, so the result is deterministic.
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal object: %w", err)
+	}
+
+	h := sha256.New()
+	h.Write([]byte(ns.UID))
+	h.Write([]byte{0})
+	h.Write([]byte(ns.ResourceVersion))
+	h.Write([]byte{0})
+	h.Write(raw)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
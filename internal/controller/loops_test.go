@@ -0,0 +1,146 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestLoopDetector(t *testing.T) {
+	t.Parallel()
+
+	key := types.NamespacedName{Namespace: "test-namespace", Name: "test"}
+
+	t.Run("non-deterministic output", func(t *testing.T) {
+		t.Parallel()
+
+		d := newLoopDetector()
+
+		for i := range loopThreshold {
+			offending := d.observe(key, "inputs", map[string]string{
+				"static": "value",
+				"time":   fmt.Sprint(i),
+			})
+			assert.Empty(t, offending, "loop reported too early at iteration %d", i)
+		}
+
+		offending := d.observe(key, "inputs", map[string]string{
+			"static": "value",
+			"time":   "last",
+		})
+		assert.Equal(t, []string{"time"}, offending)
+	})
+
+	t.Run("deterministic output", func(t *testing.T) {
+		t.Parallel()
+
+		d := newLoopDetector()
+
+		for range 2 * loopThreshold {
+			offending := d.observe(key, "inputs", map[string]string{"static": "value"})
+			assert.Empty(t, offending)
+		}
+	})
+
+	t.Run("changing inputs", func(t *testing.T) {
+		t.Parallel()
+
+		d := newLoopDetector()
+
+		for i := range 2 * loopThreshold {
+			offending := d.observe(key, fmt.Sprint(i), map[string]string{"value": fmt.Sprint(i)})
+			assert.Empty(t, offending)
+		}
+	})
+
+	t.Run("forget", func(t *testing.T) {
+		t.Parallel()
+
+		d := newLoopDetector()
+
+		for i := range loopThreshold + 1 {
+			d.observe(key, "inputs", map[string]string{"time": fmt.Sprint(i)})
+		}
+
+		d.forget(key)
+
+		offending := d.observe(key, "inputs", map[string]string{"time": "after"})
+		assert.Empty(t, offending)
+	})
+
+	t.Run("nil detector", func(t *testing.T) {
+		t.Parallel()
+
+		var d *loopDetector
+
+		assert.Empty(t, d.observe(key, "inputs", map[string]string{"key": "value"}))
+		d.forget(key)
+	})
+}
+
+func TestLoopInputs(t *testing.T) {
+	t.Parallel()
+
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "test-namespace",
+			UID:             "test-uid",
+			ResourceVersion: "1",
+		},
+	}
+
+	newObject := func(resourceVersion, managedValue, otherValue string) map[string]any {
+		return map[string]any{
+			"metadata": map[string]any{
+				"name":            "test",
+				"resourceVersion": resourceVersion,
+				"annotations": map[string]any{
+					"managed":              managedValue,
+					"other":                otherValue,
+					lastAppliedAnnotations: "managed=" + managedValue,
+				},
+			},
+		}
+	}
+
+	managed := map[string]string{"managed": "value"}
+
+	first, err := loopInputs(ns, newObject("1", "a", "x"), managed, lastAppliedAnnotations)
+	require.NoError(t, err)
+
+	second, err := loopInputs(ns, newObject("2", "b", "x"), managed, lastAppliedAnnotations)
+	require.NoError(t, err)
+	assert.Equal(t, first, second, "managed keys and resource version must not affect the inputs")
+
+	third, err := loopInputs(ns, newObject("3", "b", "y"), managed, lastAppliedAnnotations)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, third, "unmanaged keys must affect the inputs")
+
+	ns.ResourceVersion = "2"
+
+	fourth, err := loopInputs(ns, newObject("1", "a", "x"), managed, lastAppliedAnnotations)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, fourth, "namespace changes must affect the inputs")
+}
@@ -30,9 +30,51 @@ var (
 		},
 		[]string{"source_namespace"},
 	)
+	templateLimitErrorsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "template_limit_exceeded_total",
+			Help: "Total count of template executions aborted due to exceeded limits",
+		},
+		[]string{"source_namespace", "limit"},
+	)
+	templateCacheCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "template_cache_requests_total",
+			Help: "Total count of template cache lookups, partitioned by result",
+		},
+		[]string{"result"},
+	)
+	updateLoopsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "update_loops_detected_total",
+			Help: "Total count of skipped updates due to detected update loops",
+		},
+		[]string{"source_namespace"},
+	)
+	pendingTypesGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "pending_types",
+			Help: "Configured types that are not served by the cluster, e.g. because their CRD is not installed",
+		},
+		[]string{"group_kind"},
+	)
+	missingPermissionsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "missing_permissions",
+			Help: "Verbs the manager is not allowed to use on configured types, which are not reconciled",
+		},
+		[]string{"group_kind", "verb"},
+	)
 )
 
 func init() {
 	// Register custom metrics with the global prometheus registry
-	metrics.Registry.MustRegister(validationErrorsCounter)
+	metrics.Registry.MustRegister(
+		validationErrorsCounter,
+		templateLimitErrorsCounter,
+		templateCacheCounter,
+		updateLoopsCounter,
+		pendingTypesGauge,
+		missingPermissionsGauge,
+	)
 }
@@ -17,7 +17,6 @@ limitations under the License.
 package controller
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -29,19 +28,21 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apivalidation "k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/anza-labs/scribe/internal/config"
 )
 
 // +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
 
-const (
-	annotations            = "scribe.anza-labs.dev/annotations"
-	lastAppliedAnnotations = "scribe.anza-labs.dev/last-applied-annotations"
-)
+// OptInRequired is the reason of the event emitted on namespaces with a block, but without the opt-in label.
+const OptInRequired = "OptInRequired"
 
 var ErrSkipReconciliation = errors.New("skip reconciliation")
 
@@ -49,6 +50,7 @@ var ErrSkipReconciliation = errors.New("skip reconciliation")
 type getLister interface {
 	Get(context.Context, client.ObjectKey, client.Object, ...client.GetOption) error
 	listObjects(context.Context, string) ([]types.NamespacedName, error)
+	enqueuesNamespace(*corev1.Namespace) bool
 }
 
 // mapFunc returns a function that triggers a reconcile request based on the provided lister.
@@ -68,7 +70,7 @@ func mapFunc(l getLister) func(ctx context.Context, obj client.Object) []reconci
 			return nil
 		}
 
-		if _, ok := ns.Annotations[annotations]; !ok {
+		if !l.enqueuesNamespace(ns) {
 			log.V(3).Info("Skipping unmanaged namespace")
 			return nil
 		}
@@ -96,11 +98,84 @@ func mapFunc(l getLister) func(ctx context.Context, obj client.Object) []reconci
 type NamespaceScope struct {
 	client.Client
 	namespace *corev1.Namespace
+	templates *template.Template
+	limits    config.Limits
+	fields    config.Fields
+	cache     *TemplateCache
+	keys      AnnotationKeys
+	policy    config.Policy
+	optIn     bool
+	filter    *ObjectFilter
+	expected  map[string]string
+	invalid   *ValidationErrors
+}
+
+// NamespaceScopeOption configures optional behavior of the NamespaceScope.
+type NamespaceScopeOption func(*NamespaceScope)
+
+// WithSharedTemplates pre-seeds the template parser with the given named templates,
+// so namespace annotations can call them with {{ template "name" . }}.
+func WithSharedTemplates(tpl *template.Template) NamespaceScopeOption {
+	return func(ss *NamespaceScope) {
+		ss.templates = tpl
+	}
+}
+
+// WithLimits bounds the resources used by template execution.
+func WithLimits(limits config.Limits) NamespaceScopeOption {
+	return func(ss *NamespaceScope) {
+		ss.limits = limits.WithDefaults()
+	}
+}
+
+// WithFields restricts the object fields that templates and rules can read.
+func WithFields(fields config.Fields) NamespaceScopeOption {
+	return func(ss *NamespaceScope) {
+		ss.fields = fields
+	}
+}
+
+// WithTemplateCache reuses parsed templates, and the results of static templates,
+// across namespace scopes sharing the same cache.
+func WithTemplateCache(cache *TemplateCache) NamespaceScopeOption {
+	return func(ss *NamespaceScope) {
+		ss.cache = cache
+	}
+}
+
+// WithKeys sets the annotation keys read from the namespace and written to objects.
+func WithKeys(keys AnnotationKeys) NamespaceScopeOption {
+	return func(ss *NamespaceScope) {
+		ss.keys = keys.withDefaults()
+	}
+}
+
+// WithPolicy restricts the keys propagated from the namespace.
+func WithPolicy(policy config.Policy) NamespaceScopeOption {
+	return func(ss *NamespaceScope) {
+		ss.policy = policy
+	}
+}
+
+// WithOptIn ignores the block of the namespace, unless the namespace is labeled with
+// the opt-in label, so only those who can label namespaces can enable the propagation.
+func WithOptIn() NamespaceScopeOption {
+	return func(ss *NamespaceScope) {
+		ss.optIn = true
+	}
+}
+
+// WithNamespaceFilter ignores the block of the namespace, unless the namespace is selected by the filter,
+// so the keys applied before the namespace stopped being selected are removed.
+func WithNamespaceFilter(filter *ObjectFilter) NamespaceScopeOption {
+	return func(ss *NamespaceScope) {
+		ss.filter = filter
+	}
 }
 
 // NewNamespaceScope creates a new instance of NamespaceScope for the given namespace name.
-func NewNamespaceScope(c client.Client, ns string) *NamespaceScope {
-	return &NamespaceScope{
+func NewNamespaceScope(c client.Client, ns string, opts ...NamespaceScopeOption) *NamespaceScope {
+	ss := &NamespaceScope{
 		Client: c,
 		namespace: &corev1.Namespace{
 			ObjectMeta: metav1.ObjectMeta{
@@ -108,7 +183,15 @@ func NewNamespaceScope(c client.Client, ns string) *NamespaceScope {
 				Name:      ns,
 			},
 		},
+		limits: config.Limits{}.WithDefaults(),
+		keys:   DefaultAnnotationKeys,
+	}
+
+	for _, opt := range opts {
+		opt(ss)
 	}
+
+	return ss
 }
 
 // UpdateAnnotations updates the annotations of a namespace.
@@ -122,20 +205,29 @@ func (ss *NamespaceScope) UpdateAnnotations(
 		return nil, fmt.Errorf("unable to get namespace: %w", err)
 	}
 
-	tpl, err := template.New("").Parse(ss.namespace.Annotations[annotations])
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse template: %w", err)
+	// Retrieve expected and last-applied annotations. Without the required opt-in, or once the namespace
+	// is no longer selected, nothing is expected, so the keys applied before are removed.
+	expected := map[string]string{}
+	var keys blockKeys
+	if ss.OptedIn() && ss.filter.IncludesNamespace(ss.namespace) {
+		var entry *templateEntry
+		var err error
+		expected, entry, err = ss.render(ctx, object)
+		if err != nil {
+			return nil, err
+		}
+		keys = entry.keys
 	}
 
-	buf := new(bytes.Buffer)
-	err = tpl.Execute(buf, object)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute template: %w", err)
-	}
+	// Rejected keys are never applied, nor recorded as applied.
+	expected, ss.invalid = ValidateAnnotations(expected,
+		WithKeyPolicies(ss.policy.ForNamespace(ss.namespace.Labels)...),
+		WithValueRules(ss.policy.Values...),
+		WithRestrictedKeys(ss.policy, keys.literal),
+	)
+	ss.expected = expected
 
-	// Retrieve expected and last-applied annotations
-	expected := unmarshalAnnotations(buf.String())
-	lastApplied := unmarshalAnnotations(objAnnotations[lastAppliedAnnotations])
+	lastApplied := unmarshalAnnotations(objAnnotations[ss.keys.LastApplied])
 	if len(expected) == 0 && len(lastApplied) == 0 {
 		return nil, ErrSkipReconciliation
 	}
@@ -156,20 +248,233 @@ func (ss *NamespaceScope) UpdateAnnotations(
 		}
 	}
 
+	// Only the keys managed by this instance are recorded, so instances with different
+	// classes never remove the keys applied by each other.
 	final := make(map[string]string)
 	maps.Copy(final, results)
-	delete(results, lastAppliedAnnotations)
+	final[ss.keys.LastApplied] = marshalAnnotations(expected)
 
-	final[lastAppliedAnnotations] = marshalAnnotations(results)
-
-	err = apivalidation.ValidateAnnotationsSize(final)
-	if err != nil {
+	if err := apivalidation.ValidateAnnotationsSize(final); err != nil {
 		return nil, fmt.Errorf("size validation failed: %w", err)
 	}
 
 	return final, nil
 }
 
+// OptedIn reports whether the block of the namespace is honored: either no opt-in is required,
+// or the namespace is labeled with the opt-in label.
+func (ss *NamespaceScope) OptedIn() bool {
+	return !ss.optIn || ss.namespace.Labels[ss.keys.Enabled] == "true"
+}
+
+// ExpectedAnnotations returns the annotations rendered from the namespace block
+// by the last call to UpdateAnnotations, i.e. the keys managed on the object.
+func (ss *NamespaceScope) ExpectedAnnotations() map[string]string {
+	return ss.expected
+}
+
+// DryRunResult is the result of rendering the block of a namespace against a sample object.
+type DryRunResult struct {
+	// Annotations are the annotations rendered for the sample object.
+	Annotations map[string]string
+	// Keys are the keys the block can produce: the rendered keys, and the keys written literally
+	// in the block, including those in branches that are not taken for the sample object.
+	Keys []string
+	// BuiltKeys reports whether some keys are built by actions, e.g. {{ .metadata.name }}=value,
+	// so the keys produced for other objects may differ from Keys.
+	BuiltKeys bool
+	// Warnings are the errors executing the template against the sample object.
+	Warnings []string
+}
+
+// DryRun renders the block of the namespace against a sample object, and validates the result,
+// without reading the namespace from the API server. Problems with the block itself, e.g. syntax
+// errors, broken rules, exceeded limits or rejected keys, are returned as an error. Errors executing
+// the template, and values not satisfying the value rules, are returned as warnings, as they may
+// depend on the object, unless the block never reads the object.
+func (ss *NamespaceScope) DryRun(ctx context.Context, ns *corev1.Namespace, object map[string]any) (*DryRunResult, error) {
+	ss.namespace = ns.DeepCopy()
+	result := &DryRunResult{}
+
+	if _, ok := ss.namespace.Annotations[ss.keys.Annotations]; !ok {
+		return result, nil
+	}
+
+	expected, entry, err := ss.render(ctx, object)
+	if err != nil {
+		var execErr template.ExecError
+		if !errors.As(err, &execErr) {
+			return nil, err
+		}
+		result.Warnings = append(result.Warnings, err.Error())
+	}
+	keys := entry.keys
+
+	opts := []ValidationOption{
+		WithKeyPolicies(ss.policy.ForNamespace(ss.namespace.Labels)...),
+		WithRestrictedKeys(ss.policy, keys.literal),
+	}
+	if entry.static {
+		opts = append(opts, WithValueRules(ss.policy.Values...))
+	}
+
+	_, invalid := ValidateAnnotations(expected, opts...)
+	if invalid != nil {
+		return nil, invalid
+	}
+
+	if !entry.static {
+		if _, invalid := ValidateAnnotations(expected, WithValueRules(ss.policy.Values...)); invalid != nil {
+			for _, item := range invalid.Items {
+				result.Warnings = append(result.Warnings, item.Message())
+			}
+		}
+	}
+
+	result.Annotations = expected
+	result.Keys = slices.Clone(keys.literal)
+	result.BuiltKeys = keys.built
+	for k := range expected {
+		if !slices.Contains(result.Keys, k) {
+			result.Keys = append(result.Keys, k)
+		}
+	}
+	slices.Sort(result.Keys)
+
+	return result, nil
+}
+
+// ValidationErrors returns the keys rejected by the last call to UpdateAnnotations,
+// because they are invalid or not accepted by the policy.
+func (ss *NamespaceScope) ValidationErrors() *ValidationErrors {
+	return ss.invalid
+}
+
+// render executes the namespace template for the object and applies the rules to the result.
+// It returns the parsed template too, once parsed, even if the execution fails. Static templates,
+// which never read the object, are rendered once per namespace version and the result is shared by all objects.
+func (ss *NamespaceScope) render(ctx context.Context, object map[string]any) (map[string]string, *templateEntry, error) {
+	text := ss.namespace.Annotations[ss.keys.Annotations]
+	if err := checkTemplateLength(text, ss.limits); err != nil {
+		return nil, nil, err
+	}
+
+	entry, err := ss.cache.get(ss.namespace, func() (*template.Template, error) {
+		tpl, err := ss.parseTemplate(text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template: %w", err)
+		}
+		return tpl, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, hasRules := ss.namespace.Annotations[ss.keys.Rules]
+
+	var data map[string]any
+	if !entry.static || hasRules {
+		data, err = filterFields(object, ss.fields)
+		if err != nil {
+			return nil, entry, err
+		}
+	}
+
+	var out string
+	if entry.static {
+		out, err = entry.render(func() (string, error) {
+			return executeTemplate(ctx, entry.tpl, nil, ss.limits)
+		})
+	} else {
+		out, err = entry.execute(func() (string, error) {
+			return executeTemplate(ctx, entry.tpl, data, ss.limits)
+		})
+	}
+	if err != nil {
+		return nil, entry, err
+	}
+
+	expected := unmarshalAnnotations(out)
+	if err := ss.applyRules(ctx, expected, data); err != nil {
+		return nil, entry, err
+	}
+
+	return expected, entry, nil
+}
+
+// applyRules removes keys from the expected annotations whose rule conditions are not met.
+// A key may be attached to multiple rules, in which case all of them must evaluate to true.
+func (ss *NamespaceScope) applyRules(ctx context.Context, expected map[string]string, object map[string]any) error {
+	raw, ok := ss.namespace.Annotations[ss.keys.Rules]
+	if !ok {
+		return nil
+	}
+
+	rr, err := unmarshalRules(raw)
+	if err != nil {
+		return err
+	}
+
+	evaluator, err := defaultRuleEvaluator()
+	if err != nil {
+		return err
+	}
+
+	ns, err := runtime.DefaultUnstructuredConverter.ToUnstructured(ss.namespace)
+	if err != nil {
+		return fmt.Errorf("failed to convert namespace: %w", err)
+	}
+
+	gvk := (&unstructured.Unstructured{Object: object}).GroupVersionKind()
+	vars := map[string]any{
+		"object":          object,
+		"namespaceObject": ns,
+		"gvk": map[string]string{
+			"group":   gvk.Group,
+			"version": gvk.Version,
+			"kind":    gvk.Kind,
+		},
+	}
+
+	log := log.FromContext(ctx)
+
+	for _, rule := range rr {
+		// Compile errors are configuration errors and are reported to the caller.
+		if _, err := evaluator.Compile(rule.When); err != nil {
+			return err
+		}
+
+		matched, err := evaluator.Evaluate(rule.When, vars)
+		if err != nil {
+			// Evaluation errors, e.g. missing fields, are treated as unmet conditions.
+			log.V(1).Info("Rule evaluation failed", "expression", rule.When, "error", err.Error())
+		}
+
+		if !matched {
+			for _, key := range rule.Keys {
+				delete(expected, key)
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseTemplate parses the namespace annotation block. If shared templates are configured,
+// they are cloned first, so the block can reference them without modifying the shared set.
+func (ss *NamespaceScope) parseTemplate(text string) (*template.Template, error) {
+	if ss.templates == nil {
+		return template.New("").Parse(text)
+	}
+
+	tpl, err := ss.templates.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone shared templates: %w", err)
+	}
+
+	return tpl.Parse(text)
+}
+
 // unmarshalAnnotations parses a string containing key-value pairs into a map.
 // The input string should be formatted as comma-separated key=value pairs.
 // Newline characters are treated as commas for parsing.
@@ -18,6 +18,7 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -29,10 +30,16 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/anza-labs/scribe/internal/config"
 )
 
+// errAny is used in test cases that expect an error, but not a specific one.
+var errAny = errors.New("any error")
+
 func TestUpdateAnnotations(t *testing.T) {
 	t.Parallel()
 
@@ -43,6 +50,7 @@ func TestUpdateAnnotations(t *testing.T) {
 		// Input parameters
 		object               *corev1.Pod
 		namespaceAnnotations map[string]string
+		sharedTemplates      map[string]string
 		// Expected output
 		expectedResult map[string]string
 		expectedError  error
@@ -93,6 +101,67 @@ func TestUpdateAnnotations(t *testing.T) {
 				}),
 			},
 		},
+		"add annotations with shared template": {
+			object: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-pod",
+					Annotations: map[string]string{},
+				},
+			},
+			namespaceAnnotations: map[string]string{
+				annotations: `{{ template "pod-name" . }},key2=value2`,
+			},
+			sharedTemplates: map[string]string{
+				"pod-name": "key1={{ .metadata.name }}",
+			},
+			expectedResult: map[string]string{
+				"key1": "test-pod",
+				"key2": "value2",
+				lastAppliedAnnotations: marshalAnnotations(map[string]string{
+					"key1": "test-pod",
+					"key2": "value2",
+				}),
+			},
+		},
+		"add annotations with rules": {
+			object: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-pod",
+					Labels:      map[string]string{"team": "platform"},
+					Annotations: map[string]string{},
+				},
+			},
+			namespaceAnnotations: map[string]string{
+				annotations: marshalAnnotations(map[string]string{
+					"key1": "value1",
+					"key2": "value2",
+					"key3": "value3",
+				}),
+				rules: `- when: has(object.metadata.labels.team)
+  keys: [key1]
+- when: object.metadata.name == "other-pod"
+  keys: [key2, key3]`,
+			},
+			expectedResult: map[string]string{
+				"key1": "value1",
+				lastAppliedAnnotations: marshalAnnotations(map[string]string{
+					"key1": "value1",
+				}),
+			},
+		},
+		"invalid rule expression": {
+			object: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{},
+				},
+			},
+			namespaceAnnotations: map[string]string{
+				annotations: "key1=value1",
+				rules: `- when: object.metadata.name ==
+  keys: [key1]`,
+			},
+			expectedError: errAny,
+		},
 		"append annotations": {
 			object: &corev1.Pod{
 				ObjectMeta: metav1.ObjectMeta{
@@ -187,14 +256,22 @@ func TestUpdateAnnotations(t *testing.T) {
 				}).
 				Build()
 
-			nss := NewNamespaceScope(fakeClient, "test-namespace")
+			cfg := config.Config{Templates: tc.sharedTemplates}
+			tpl, err := cfg.SharedTemplates()
+			require.NoError(t, err)
+
+			nss := NewNamespaceScope(fakeClient, "test-namespace", WithSharedTemplates(tpl))
 
 			unstructuredObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(tc.object)
 			require.NoError(t, err)
 
 			result, err := nss.UpdateAnnotations(context.Background(), tc.object.ObjectMeta.Annotations, unstructuredObj)
 
-			assert.ErrorIs(t, err, tc.expectedError)
+			if errors.Is(tc.expectedError, errAny) {
+				assert.Error(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.expectedError)
+			}
 			assert.Equal(t, tc.expectedResult, result)
 		})
 	}
@@ -276,7 +353,12 @@ func TestMapFunc(t *testing.T) {
 
 	for name, tc := range map[string]struct {
 		namespaceAnnotations map[string]string
+		namespaceLabels      map[string]string
+		requireOptIn         bool
+		namespaceSelector    map[string]string
+		wasManaged           bool
 		expectedRequests     []reconcile.Request
+		expectedEvents       int
 	}{
 		"managed namespace with objects": {
 			namespaceAnnotations: map[string]string{
@@ -291,6 +373,65 @@ func TestMapFunc(t *testing.T) {
 			namespaceAnnotations: map[string]string{},
 			expectedRequests:     nil,
 		},
+		"opted in namespace": {
+			namespaceAnnotations: map[string]string{
+				annotations: "key=value",
+			},
+			namespaceLabels: map[string]string{
+				enabled: "true",
+			},
+			requireOptIn: true,
+			expectedRequests: []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: "test-namespace", Name: "pod1"}},
+				{NamespacedName: types.NamespacedName{Namespace: "test-namespace", Name: "pod2"}},
+			},
+		},
+		"namespace without opt-in": {
+			namespaceAnnotations: map[string]string{
+				annotations: "key=value",
+			},
+			requireOptIn:     true,
+			expectedRequests: nil,
+			expectedEvents:   1,
+		},
+		"namespace no longer managed": {
+			namespaceAnnotations: map[string]string{},
+			wasManaged:           true,
+			expectedRequests: []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: "test-namespace", Name: "pod1"}},
+				{NamespacedName: types.NamespacedName{Namespace: "test-namespace", Name: "pod2"}},
+			},
+		},
+		"namespace opted out": {
+			namespaceAnnotations: map[string]string{
+				annotations: "key=value",
+			},
+			requireOptIn: true,
+			wasManaged:   true,
+			expectedRequests: []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: "test-namespace", Name: "pod1"}},
+				{NamespacedName: types.NamespacedName{Namespace: "test-namespace", Name: "pod2"}},
+			},
+			expectedEvents: 1,
+		},
+		"namespace not selected": {
+			namespaceAnnotations: map[string]string{
+				annotations: "key=value",
+			},
+			namespaceSelector: map[string]string{"team": "a"},
+			expectedRequests:  nil,
+		},
+		"namespace no longer selected": {
+			namespaceAnnotations: map[string]string{
+				annotations: "key=value",
+			},
+			namespaceSelector: map[string]string{"team": "a"},
+			wasManaged:        true,
+			expectedRequests: []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: "test-namespace", Name: "pod1"}},
+				{NamespacedName: types.NamespacedName{Namespace: "test-namespace", Name: "pod2"}},
+			},
+		},
 	} {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
@@ -304,6 +445,7 @@ func TestMapFunc(t *testing.T) {
 							Name:        "test-namespace",
 							Namespace:   "test-namespace",
 							Annotations: tc.namespaceAnnotations,
+							Labels:      tc.namespaceLabels,
 						},
 					},
 					&corev1.Pod{
@@ -321,11 +463,25 @@ func TestMapFunc(t *testing.T) {
 				).
 				Build()
 
+			recorder := record.NewFakeRecorder(10)
+
 			// Create a mock lister
 			lister := &UnstructuredReconciler{
-				Client: fakeClient,
-				Scheme: scheme,
-				gvk:    corev1.SchemeGroupVersion.WithKind("Pod"),
+				Client:        fakeClient,
+				Scheme:        scheme,
+				RequireOptIn:  tc.requireOptIn,
+				OptInWarnings: NewOptInWarnings(recorder),
+				gvk:           corev1.SchemeGroupVersion.WithKind("Pod"),
+			}
+			if tc.namespaceSelector != nil {
+				filter, err := NewObjectFilter(config.Filter{
+					NamespaceSelector: &config.LabelSelector{MatchLabels: tc.namespaceSelector},
+				})
+				require.NoError(t, err)
+				lister.Filter = filter
+			}
+			if tc.wasManaged {
+				lister.managed.Store("test-namespace", struct{}{})
 			}
 
 			// Create the map function
@@ -344,6 +500,200 @@ func TestMapFunc(t *testing.T) {
 
 			// Verify the results
 			assert.ElementsMatch(t, tc.expectedRequests, requests)
+
+			// Namespaces that are no longer managed are enqueued once, and reported once.
+			requests = mapFn(context.Background(), testObject)
+			if tc.wasManaged {
+				assert.Empty(t, requests)
+			} else {
+				assert.ElementsMatch(t, tc.expectedRequests, requests)
+			}
+			assert.Len(t, recorder.Events, tc.expectedEvents)
+		})
+	}
+}
+
+func TestNamespaceScopeOptIn(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		namespaceLabels map[string]string
+		expectedResult  map[string]string
+	}{
+		"opted in": {
+			namespaceLabels: map[string]string{enabled: "true"},
+			expectedResult: map[string]string{
+				"key1":                 "value1",
+				"key2":                 "value2",
+				lastAppliedAnnotations: "key2=value2",
+			},
+		},
+		"opted out": {
+			namespaceLabels: map[string]string{enabled: "false"},
+			expectedResult: map[string]string{
+				"key1":                 "value1",
+				lastAppliedAnnotations: "",
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			fakeClient := fake.NewClientBuilder().
+				WithObjects(&corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-namespace",
+						Namespace: "test-namespace",
+						Labels:    tc.namespaceLabels,
+						Annotations: map[string]string{
+							annotations: "key2=value2",
+						},
+					},
+				}).
+				Build()
+
+			nss := NewNamespaceScope(fakeClient, "test-namespace", WithOptIn())
+
+			// key1 was set by hand, and key2 was applied before.
+			result, err := nss.UpdateAnnotations(context.Background(), map[string]string{
+				"key1":                 "value1",
+				"key2":                 "value2",
+				lastAppliedAnnotations: "key2=value2",
+			}, map[string]any{})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedResult, result)
+		})
+	}
+}
+
+func TestNamespaceScopeNamespaceFilter(t *testing.T) {
+	t.Parallel()
+
+	filter, err := NewObjectFilter(config.Filter{
+		NamespaceSelector: &config.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		namespaceLabels map[string]string
+		expectedResult  map[string]string
+	}{
+		"selected": {
+			namespaceLabels: map[string]string{"team": "a"},
+			expectedResult: map[string]string{
+				"key1":                 "value1",
+				"key2":                 "value2",
+				lastAppliedAnnotations: "key2=value2",
+			},
+		},
+		"no longer selected": {
+			namespaceLabels: map[string]string{"team": "b"},
+			expectedResult: map[string]string{
+				"key1":                 "value1",
+				lastAppliedAnnotations: "",
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			fakeClient := fake.NewClientBuilder().
+				WithObjects(&corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-namespace",
+						Namespace: "test-namespace",
+						Labels:    tc.namespaceLabels,
+						Annotations: map[string]string{
+							annotations: "key2=value2",
+						},
+					},
+				}).
+				Build()
+
+			nss := NewNamespaceScope(fakeClient, "test-namespace", WithNamespaceFilter(filter))
+
+			// key1 was set by hand, and key2 was applied before.
+			result, err := nss.UpdateAnnotations(context.Background(), map[string]string{
+				"key1":                 "value1",
+				"key2":                 "value2",
+				lastAppliedAnnotations: "key2=value2",
+			}, map[string]any{})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedResult, result)
+		})
+	}
+}
+
+func TestNamespaceScopeRestrictedKeys(t *testing.T) {
+	t.Parallel()
+
+	policy := config.Policy{
+		Restricted: []config.RestrictedKeys{
+			{Keys: []string{"sidecar.istio.io/*"}, Groups: []string{"platform"}},
+		},
+	}
+
+	pod := map[string]any{
+		"metadata": map[string]any{
+			"name":        "test-pod",
+			"labels":      map[string]any{"app": "test"},
+			"annotations": map[string]any{"team": "a,sidecar.istio.io/inject=false"},
+		},
+	}
+
+	for name, tc := range map[string]struct {
+		block            string
+		expectedResult   map[string]string
+		expectedError    error
+		expectedRejected []string
+	}{
+		"literal key": {
+			block: `{{ if .metadata.labels.app }}sidecar.istio.io/inject=false{{ end }}`,
+			expectedResult: map[string]string{
+				"sidecar.istio.io/inject": "false",
+				lastAppliedAnnotations:    "sidecar.istio.io/inject=false",
+			},
+		},
+		"key built by action": {
+			block:            `{{ if .metadata.labels.app }}{{ "sidecar.istio.io/inject" }}=false{{ end }}`,
+			expectedError:    ErrSkipReconciliation,
+			expectedRejected: []string{"sidecar.istio.io/inject"},
+		},
+		"key injected through value": {
+			block: `team={{ .metadata.annotations.team }}`,
+			expectedResult: map[string]string{
+				"team":                 "a",
+				lastAppliedAnnotations: "team=a",
+			},
+			expectedRejected: []string{"sidecar.istio.io/inject"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			fakeClient := fake.NewClientBuilder().
+				WithObjects(&corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "test-namespace",
+						Namespace:   "test-namespace",
+						Annotations: map[string]string{annotations: tc.block},
+					},
+				}).
+				Build()
+
+			nss := NewNamespaceScope(fakeClient, "test-namespace", WithPolicy(policy))
+
+			result, err := nss.UpdateAnnotations(context.Background(), map[string]string{}, pod)
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.expectedResult, result)
+
+			var rejected []string
+			if invalid := nss.ValidationErrors(); invalid != nil {
+				for _, item := range invalid.Items {
+					rejected = append(rejected, item.Key)
+				}
+			}
+			assert.Equal(t, tc.expectedRejected, rejected)
 		})
 	}
 }
@@ -0,0 +1,121 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=selfsubjectaccessreviews,verbs=create
+
+// MissingPermissions is the reason of the event emitted when the manager is missing the permissions
+// required to reconcile a type.
+const MissingPermissions = "MissingPermissions"
+
+// permissionsTTL is how long granted permissions are trusted before they are reviewed again.
+const permissionsTTL = time.Minute
+
+// reviewFunc reviews whether the manager can perform the action described by the attributes.
+type reviewFunc func(context.Context, *authorizationv1.ResourceAttributes) (*authorizationv1.SubjectAccessReviewStatus, error)
+
+// selfSubjectAccessReview reviews actions with SelfSubjectAccessReviews created by the client.
+func selfSubjectAccessReview(c client.Client) reviewFunc {
+	return func(ctx context.Context, attrs *authorizationv1.ResourceAttributes) (*authorizationv1.SubjectAccessReviewStatus, error) {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: attrs},
+		}
+
+		if err := c.Create(ctx, review); err != nil {
+			return nil, fmt.Errorf("unable to review access: %w", err)
+		}
+
+		return &review.Status, nil
+	}
+}
+
+// permissionError lists the verbs the manager is missing on the resource of a type.
+type permissionError struct {
+	resource  schema.GroupResource
+	namespace string
+	verbs     []string
+	reason    string
+}
+
+func (e *permissionError) Error() string {
+	scope := "cluster-wide"
+	if e.namespace != "" {
+		scope = fmt.Sprintf("in the namespace %q", e.namespace)
+	}
+
+	msg := fmt.Sprintf("missing permissions to %s %s %s", strings.Join(e.verbs, ", "), e.resource, scope)
+	if e.reason != "" {
+		msg += ": " + e.reason
+	}
+
+	return msg
+}
+
+// checkPermissions reviews the verbs required to reconcile the resource, cluster-wide, or in every
+// namespace if namespaces are given. It returns a permissionError for the first scope missing any verb.
+func checkPermissions(
+	ctx context.Context,
+	review reviewFunc,
+	gvr schema.GroupVersionResource,
+	namespaces []string,
+) error {
+	if len(namespaces) == 0 {
+		namespaces = []string{""}
+	}
+
+	for _, ns := range namespaces {
+		var missing []string
+		var reason string
+
+		for _, verb := range requiredVerbs {
+			status, err := review(ctx, &authorizationv1.ResourceAttributes{
+				Namespace: ns,
+				Verb:      verb,
+				Group:     gvr.Group,
+				Version:   gvr.Version,
+				Resource:  gvr.Resource,
+			})
+			if err != nil {
+				return err
+			}
+
+			if !status.Allowed {
+				missing = append(missing, verb)
+				if reason == "" {
+					reason = status.Reason
+				}
+			}
+		}
+
+		if len(missing) > 0 {
+			return &permissionError{resource: gvr.GroupResource(), namespace: ns, verbs: missing, reason: reason}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/anza-labs/scribe/internal/config"
+)
+
+// fakeReview allows the verbs listed for every resource and namespace, e.g. "deployments/team-a".
+func fakeReview(allowed map[string][]string) reviewFunc {
+	return func(_ context.Context, attrs *authorizationv1.ResourceAttributes) (*authorizationv1.SubjectAccessReviewStatus, error) {
+		key := attrs.Resource
+		if attrs.Namespace != "" {
+			key += "/" + attrs.Namespace
+		}
+
+		if slices.Contains(allowed[key], attrs.Verb) {
+			return &authorizationv1.SubjectAccessReviewStatus{Allowed: true}, nil
+		}
+
+		return &authorizationv1.SubjectAccessReviewStatus{Reason: "no RBAC policy matched"}, nil
+	}
+}
+
+func TestCheckPermissions(t *testing.T) {
+	t.Parallel()
+
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	all := []string{"get", "list", "watch", "update"}
+
+	for name, tc := range map[string]struct {
+		allowed     map[string][]string
+		namespaces  []string
+		expectedErr string
+	}{
+		"allowed cluster-wide": {
+			allowed: map[string][]string{"deployments": all},
+		},
+		"missing verbs cluster-wide": {
+			allowed:     map[string][]string{"deployments": {"get", "list", "watch"}},
+			expectedErr: "missing permissions to update deployments.apps cluster-wide: no RBAC policy matched",
+		},
+		"allowed in namespaces": {
+			allowed:    map[string][]string{"deployments/a": all, "deployments/b": all},
+			namespaces: []string{"a", "b"},
+		},
+		"missing verbs in a namespace": {
+			allowed:     map[string][]string{"deployments/a": all},
+			namespaces:  []string{"a", "b"},
+			expectedErr: `missing permissions to get, list, watch, update deployments.apps in the namespace "b": no RBAC policy matched`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := checkPermissions(context.Background(), fakeReview(tc.allowed), deployments, tc.namespaces)
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestControllerSetAuthorize(t *testing.T) {
+	t.Parallel()
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Pod"}, meta.RESTScopeNamespace)
+
+	recorder := record.NewFakeRecorder(10)
+
+	s := &ControllerSet{
+		ctx:      context.Background(),
+		mapper:   meta.MultiRESTMapper{mapper},
+		review:   fakeReview(map[string][]string{"pods": {"get", "list", "watch", "update"}}),
+		recorder: recorder,
+		object:   &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "scribe", Namespace: "scribe-system"}},
+	}
+
+	types := []config.Type{
+		{APIVersion: "apps/v1", Kind: "Deployment"},
+		{APIVersion: "v1", Kind: "Pod"},
+	}
+
+	permitted, errs := s.authorize(types, s.reviewPermissions(types))
+	assert.Equal(t, []config.Type{{APIVersion: "v1", Kind: "Pod"}}, permitted)
+	require.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "deployments.apps")
+	assert.ErrorContains(t, s.PermissionsCheck(nil), "Deployment.apps: missing permissions to get, list, watch, update")
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, MissingPermissions)
+
+	// Missing permissions are only reported again when they change.
+	_, _ = s.authorize(types, s.reviewPermissions(types))
+	assert.Empty(t, recorder.Events)
+
+	s.review = fakeReview(map[string][]string{
+		"pods":        {"get", "list", "watch", "update"},
+		"deployments": {"get", "list", "watch", "update"},
+	})
+
+	permitted, errs = s.authorize(types, s.reviewPermissions(types))
+	assert.Equal(t, types, permitted)
+	assert.Empty(t, errs)
+	assert.NoError(t, s.PermissionsCheck(nil))
+}
+
+func TestControllerSetReviewPermissionsWithoutLock(t *testing.T) {
+	t.Parallel()
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Pod"}, meta.RESTScopeNamespace)
+
+	reviewing := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	deny := fakeReview(nil)
+
+	s := &ControllerSet{
+		ctx:    context.Background(),
+		cfg:    &config.Config{Types: []config.Type{{APIVersion: "v1", Kind: "Pod"}}},
+		mapper: meta.MultiRESTMapper{mapper},
+		review: func(ctx context.Context, attrs *authorizationv1.ResourceAttributes) (*authorizationv1.SubjectAccessReviewStatus, error) {
+			once.Do(func() { close(reviewing) })
+			<-release
+			return deny(ctx, attrs)
+		},
+		versions: make(map[schema.GroupKind]string),
+		running:  make(map[schema.GroupVersionKind]*runningController),
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.reconfigure()
+	}()
+
+	// The configuration can be read, e.g. by the webhooks, while the permissions are reviewed.
+	<-reviewing
+	assert.NotNil(t, s.Config())
+	assert.NoError(t, s.PermissionsCheck(nil))
+
+	// Missing permissions fail the type only, not the whole configuration.
+	close(release)
+	assert.NoError(t, <-done)
+	assert.Error(t, s.PermissionsCheck(nil))
+	assert.Contains(t, s.failed, schema.GroupVersionKind{Version: "v1", Kind: "Pod"})
+}
+
+func TestControllerSetGrantedPermissions(t *testing.T) {
+	t.Parallel()
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Pod"}, meta.RESTScopeNamespace)
+
+	reviews := map[string]int{}
+	allow := fakeReview(map[string][]string{"pods": {"get", "list", "watch", "update"}})
+
+	s := &ControllerSet{
+		ctx:    context.Background(),
+		mapper: meta.MultiRESTMapper{mapper},
+		review: func(ctx context.Context, attrs *authorizationv1.ResourceAttributes) (*authorizationv1.SubjectAccessReviewStatus, error) {
+			reviews[attrs.Resource]++
+			return allow(ctx, attrs)
+		},
+	}
+
+	types := []config.Type{
+		{APIVersion: "apps/v1", Kind: "Deployment"},
+		{APIVersion: "v1", Kind: "Pod"},
+	}
+
+	for range 3 {
+		s.reviewPermissions(types)
+	}
+
+	// Granted permissions are reviewed once, missing ones every time.
+	assert.Equal(t, 4, reviews["pods"])
+	assert.Equal(t, 12, reviews["deployments"])
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get
+
+// namespacePollInterval is how often the namespaces are read when they cannot be watched.
+const namespacePollInterval = 30 * time.Second
+
+// namespacePoller emits events for a fixed set of namespaces. It replaces the Namespace watch
+// when the controller is restricted to some namespaces, as watching namespaces requires
+// cluster-wide permissions, while reading a single namespace can be granted by a Role in it.
+type namespacePoller struct {
+	reader    client.Reader
+	names     []string
+	interval  time.Duration
+	handler   handler.EventHandler
+	predicate predicate.Predicate
+
+	// previous are the namespaces read by the last poll, so changes are emitted as updates.
+	previous map[string]*corev1.Namespace
+}
+
+// pollNamespaces returns a source polling the namespaces with the given names.
+func pollNamespaces(
+	reader client.Reader,
+	names []string,
+	h handler.EventHandler,
+	p predicate.Predicate,
+) source.Source {
+	poller := &namespacePoller{
+		reader:    reader,
+		names:     names,
+		interval:  namespacePollInterval,
+		handler:   h,
+		predicate: p,
+		previous:  make(map[string]*corev1.Namespace),
+	}
+
+	return source.Func(func(ctx context.Context, queue workqueue.TypedRateLimitingInterface[reconcile.Request]) error {
+		go poller.run(ctx, queue)
+		return nil
+	})
+}
+
+func (p *namespacePoller) run(ctx context.Context, queue workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.poll(ctx, queue)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll reads every namespace, and emits an event for the ones that changed since the last poll:
+// a generic event the first time a namespace is read, and an update event afterwards.
+func (p *namespacePoller) poll(ctx context.Context, queue workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	for _, name := range p.names {
+		ns := &corev1.Namespace{}
+		if err := p.reader.Get(ctx, types.NamespacedName{Name: name}, ns); err != nil {
+			if !apierrors.IsNotFound(err) {
+				log.FromContext(ctx).V(0).Error(err, "Unable to poll namespace", "namespace", name)
+			}
+			continue
+		}
+
+		previous, ok := p.previous[name]
+		if ok && previous.ResourceVersion == ns.ResourceVersion {
+			continue
+		}
+		p.previous[name] = ns
+
+		if !ok {
+			e := event.GenericEvent{Object: ns}
+			if p.predicate == nil || p.predicate.Generic(e) {
+				p.handler.Generic(ctx, e, queue)
+			}
+			continue
+		}
+
+		e := event.UpdateEvent{ObjectOld: previous, ObjectNew: ns}
+		if p.predicate == nil || p.predicate.Update(e) {
+			p.handler.Update(ctx, e, queue)
+		}
+	}
+}
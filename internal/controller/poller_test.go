@@ -0,0 +1,140 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/anza-labs/scribe/internal/config"
+)
+
+func TestNamespacePoller(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	team := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team"}}
+	other := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other"}}
+	system := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}}
+	c := fake.NewClientBuilder().WithObjects(team, other, system).Build()
+
+	// Every event is mapped to a request named after the namespace.
+	h := handler.EnqueueRequestsFromMapFunc(func(_ context.Context, obj client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: client.ObjectKey{Name: obj.GetName()}}}
+	})
+	f, err := NewObjectFilter(config.Filter{ExcludedNamespaces: []string{"kube-system"}})
+	require.NoError(t, err)
+
+	p := &namespacePoller{
+		reader:    c,
+		names:     []string{"team", "kube-system", "missing"},
+		handler:   h,
+		predicate: namespacePredicate(f),
+		previous:  make(map[string]*corev1.Namespace),
+	}
+
+	queue := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer queue.ShutDown()
+
+	drain := func() []string {
+		var names []string
+		for queue.Len() > 0 {
+			req, _ := queue.Get()
+			names = append(names, req.Name)
+			queue.Done(req)
+			queue.Forget(req)
+		}
+		return names
+	}
+
+	p.poll(ctx, queue)
+	assert.Equal(t, []string{"team"}, drain(), "first poll")
+
+	p.poll(ctx, queue)
+	assert.Empty(t, drain(), "unchanged namespaces")
+
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(team), team))
+	team.Annotations = map[string]string{"scribe.anza-labs.dev/annotations": "foo=bar"}
+	require.NoError(t, c.Update(ctx, team))
+
+	p.poll(ctx, queue)
+	assert.Equal(t, []string{"team"}, drain(), "changed namespace")
+}
+
+func TestNamespacePollerSelector(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	team := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team", Labels: map[string]string{"team": "a"}}}
+	c := fake.NewClientBuilder().WithObjects(team).Build()
+
+	h := handler.EnqueueRequestsFromMapFunc(func(_ context.Context, obj client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: client.ObjectKey{Name: obj.GetName()}}}
+	})
+	f, err := NewObjectFilter(config.Filter{
+		NamespaceSelector: &config.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+	})
+	require.NoError(t, err)
+
+	p := &namespacePoller{
+		reader:    c,
+		names:     []string{"team"},
+		handler:   h,
+		predicate: namespacePredicate(f),
+		previous:  make(map[string]*corev1.Namespace),
+	}
+
+	queue := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer queue.ShutDown()
+
+	relabel := func(value string) {
+		require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(team), team))
+		team.Labels = map[string]string{"team": value}
+		require.NoError(t, c.Update(ctx, team))
+	}
+
+	p.poll(ctx, queue)
+	assert.Equal(t, 1, queue.Len(), "selected namespace")
+	req, _ := queue.Get()
+	queue.Done(req)
+	queue.Forget(req)
+
+	// The namespace leaving the selector is emitted once, so the keys applied from it are removed.
+	relabel("b")
+	p.poll(ctx, queue)
+	assert.Equal(t, 1, queue.Len(), "namespace no longer selected")
+	req, _ = queue.Get()
+	queue.Done(req)
+	queue.Forget(req)
+
+	relabel("c")
+	p.poll(ctx, queue)
+	assert.Equal(t, 0, queue.Len(), "namespace never selected")
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+
+	"github.com/anza-labs/scribe/internal/config"
+)
+
+// builtinResources are the resources of the namespaced built-in kinds, used to generate
+// the permissions without a cluster. The kinds are also selected by include rules offline.
+var builtinResources = map[schema.GroupVersionKind]string{
+	{Version: "v1", Kind: "ConfigMap"}:                                       "configmaps",
+	{Version: "v1", Kind: "Endpoints"}:                                       "endpoints",
+	{Version: "v1", Kind: "LimitRange"}:                                      "limitranges",
+	{Version: "v1", Kind: "PersistentVolumeClaim"}:                           "persistentvolumeclaims",
+	{Version: "v1", Kind: "Pod"}:                                             "pods",
+	{Version: "v1", Kind: "PodTemplate"}:                                     "podtemplates",
+	{Version: "v1", Kind: "ReplicationController"}:                           "replicationcontrollers",
+	{Version: "v1", Kind: "ResourceQuota"}:                                   "resourcequotas",
+	{Version: "v1", Kind: "Secret"}:                                          "secrets",
+	{Version: "v1", Kind: "Service"}:                                         "services",
+	{Version: "v1", Kind: "ServiceAccount"}:                                  "serviceaccounts",
+	{Group: "apps", Version: "v1", Kind: "ControllerRevision"}:               "controllerrevisions",
+	{Group: "apps", Version: "v1", Kind: "DaemonSet"}:                        "daemonsets",
+	{Group: "apps", Version: "v1", Kind: "Deployment"}:                       "deployments",
+	{Group: "apps", Version: "v1", Kind: "ReplicaSet"}:                       "replicasets",
+	{Group: "apps", Version: "v1", Kind: "StatefulSet"}:                      "statefulsets",
+	{Group: "autoscaling", Version: "v2", Kind: "HorizontalPodAutoscaler"}:   "horizontalpodautoscalers",
+	{Group: "batch", Version: "v1", Kind: "CronJob"}:                         "cronjobs",
+	{Group: "batch", Version: "v1", Kind: "Job"}:                             "jobs",
+	{Group: "coordination.k8s.io", Version: "v1", Kind: "Lease"}:             "leases",
+	{Group: "discovery.k8s.io", Version: "v1", Kind: "EndpointSlice"}:        "endpointslices",
+	{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"}:             "ingresses",
+	{Group: "networking.k8s.io", Version: "v1", Kind: "NetworkPolicy"}:       "networkpolicies",
+	{Group: "policy", Version: "v1", Kind: "PodDisruptionBudget"}:            "poddisruptionbudgets",
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "Role"}:        "roles",
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "RoleBinding"}: "rolebindings",
+}
+
+// ConfiguredTypes returns the configured types, followed by the types selected by the include
+// rules. The include rules are resolved through discovery, or against the built-in kinds if
+// no discovery client is given.
+func ConfiguredTypes(cfg *config.Config, dc discovery.DiscoveryInterface) ([]config.Type, error) {
+	if len(cfg.Include) == 0 {
+		return cfg.Types, nil
+	}
+
+	if dc != nil {
+		discovered, err := discoverTypes(dc, cfg.Include, cfg.Exclude)
+		if err != nil {
+			return nil, err
+		}
+
+		return mergeTypes(cfg.Types, discovered), nil
+	}
+
+	var builtin []config.Type
+	for gvk := range builtinResources {
+		if config.MatchesAny(cfg.Include, gvk.GroupKind()) && !config.MatchesAny(cfg.Exclude, gvk.GroupKind()) {
+			apiVersion, kind := gvk.ToAPIVersionAndKind()
+			builtin = append(builtin, config.Type{APIVersion: apiVersion, Kind: kind})
+		}
+	}
+	slices.SortFunc(builtin, func(a, b config.Type) int {
+		return strings.Compare(a.GroupVersionKind().String(), b.GroupVersionKind().String())
+	})
+
+	return mergeTypes(cfg.Types, builtin), nil
+}
+
+// PolicyRules returns the rules granting the verbs required to reconcile the types, one rule
+// per API group. Resources are resolved through the mapper, or the built-in kinds if the mapper
+// is nil, or does not serve the kind, e.g. because its CRD is not installed yet. Otherwise, the
+// resource is guessed from the kind, and the kind is returned, so the guess can be reported.
+func PolicyRules(types []config.Type, mapper meta.RESTMapper) ([]rbacv1.PolicyRule, []schema.GroupKind, error) {
+	resources := make(map[string][]string)
+	var guessed []schema.GroupKind
+
+	for _, t := range types {
+		gvk := t.GroupVersionKind()
+
+		resource, ok, err := resourceFor(mapper, t)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			resource = guessResource(gvk)
+			guessed = append(guessed, gvk.GroupKind())
+		}
+
+		if !slices.Contains(resources[gvk.Group], resource) {
+			resources[gvk.Group] = append(resources[gvk.Group], resource)
+		}
+	}
+
+	groups := make([]string, 0, len(resources))
+	for group := range resources {
+		groups = append(groups, group)
+	}
+	slices.Sort(groups)
+
+	rules := make([]rbacv1.PolicyRule, 0, len(groups))
+	for _, group := range groups {
+		slices.Sort(resources[group])
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{group},
+			Resources: resources[group],
+			Verbs:     slices.Clone(requiredVerbs),
+		})
+	}
+
+	return rules, guessed, nil
+}
+
+// resourceFor returns the resource of the type from the mapper, or from the built-in kinds.
+// It reports whether the resource was found.
+func resourceFor(mapper meta.RESTMapper, t config.Type) (string, bool, error) {
+	gvk := t.GroupVersionKind()
+
+	if mapper != nil {
+		var versions []string
+		if !t.Unversioned() {
+			versions = append(versions, gvk.Version)
+		}
+
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), versions...)
+		if err == nil {
+			return mapping.Resource.Resource, true, nil
+		}
+		if !meta.IsNoMatchError(err) {
+			return "", false, fmt.Errorf("unable to find %s: %w", gvk.GroupKind(), err)
+		}
+	}
+
+	// Resource names do not depend on the version.
+	for builtin, resource := range builtinResources {
+		if builtin.GroupKind() == gvk.GroupKind() {
+			return resource, true, nil
+		}
+	}
+
+	return "", false, nil
+}
+
+// guessResource guesses the resource of a kind, like the API server does for CRDs by default.
+func guessResource(gvk schema.GroupVersionKind) string {
+	plural, _ := meta.UnsafeGuessKindToResource(gvk)
+	return plural.Resource
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/anza-labs/scribe/internal/config"
+)
+
+func TestConfiguredTypesOffline(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{
+		Types:   []config.Type{{APIVersion: "apps/v1", Kind: "Deployment", Fields: config.Fields{Allow: []string{"metadata"}}}},
+		Include: []config.TypeSelector{{Group: "apps"}},
+		Exclude: []config.TypeSelector{{Kind: "ControllerRevision"}},
+	}
+
+	types, err := ConfiguredTypes(cfg, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []config.Type{
+		{APIVersion: "apps/v1", Kind: "Deployment", Fields: config.Fields{Allow: []string{"metadata"}}},
+		{APIVersion: "apps/v1", Kind: "DaemonSet"},
+		{APIVersion: "apps/v1", Kind: "ReplicaSet"},
+		{APIVersion: "apps/v1", Kind: "StatefulSet"},
+	}, types)
+}
+
+func TestPolicyRules(t *testing.T) {
+	t.Parallel()
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.AddSpecific(
+		schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"},
+		schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgetz"},
+		schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widget"},
+		meta.RESTScopeNamespace,
+	)
+
+	types := []config.Type{
+		{APIVersion: "apps/v1", Kind: "Deployment"},
+		{APIVersion: "apps/v1", Kind: "StatefulSet"},
+		{Kind: "Pod"},
+		{APIVersion: "example.com/v1", Kind: "Widget"},
+		{Group: "other.example.com", Kind: "Gadget"},
+	}
+
+	verbs := []string{"get", "list", "watch", "update"}
+
+	for name, tc := range map[string]struct {
+		mapper          meta.RESTMapper
+		expectedWidgets string
+	}{
+		"offline":   {expectedWidgets: "widgets"},
+		"discovery": {mapper: mapper, expectedWidgets: "widgetz"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			rules, guessed, err := PolicyRules(types, tc.mapper)
+			require.NoError(t, err)
+
+			assert.Equal(t, []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: verbs},
+				{APIGroups: []string{"apps"}, Resources: []string{"deployments", "statefulsets"}, Verbs: verbs},
+				{APIGroups: []string{"example.com"}, Resources: []string{tc.expectedWidgets}, Verbs: verbs},
+				{APIGroups: []string{"other.example.com"}, Resources: []string{"gadgets"}, Verbs: verbs},
+			}, rules)
+
+			expectedGuessed := []schema.GroupKind{{Group: "other.example.com", Kind: "Gadget"}}
+			if tc.mapper == nil {
+				expectedGuessed = append([]schema.GroupKind{{Group: "example.com", Kind: "Widget"}}, expectedGuessed...)
+			}
+			assert.Equal(t, expectedGuessed, guessed)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ruleCostLimit bounds the runtime cost of a single rule evaluation.
+	ruleCostLimit = 1000000
+	// ruleCacheSize bounds the number of compiled expressions kept in memory.
+	ruleCacheSize = 1024
+)
+
+// Rule is a condition attached to a group of keys from the namespace annotation block.
+// The keys are propagated only if the CEL expression in When evaluates to true.
+type Rule struct {
+	When string   `json:"when"`
+	Keys []string `json:"keys"`
+}
+
+// unmarshalRules parses the YAML list of rules stored in the namespace annotation.
+func unmarshalRules(input string) ([]Rule, error) {
+	var rules []Rule
+	if err := yaml.UnmarshalStrict([]byte(input), &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse rules: %w", err)
+	}
+
+	for i, rule := range rules {
+		if rule.When == "" {
+			return nil, fmt.Errorf("rule %d: when must not be empty", i)
+		}
+		if len(rule.Keys) == 0 {
+			return nil, fmt.Errorf("rule %d: keys must not be empty", i)
+		}
+	}
+
+	return rules, nil
+}
+
+// RuleEvaluator compiles and evaluates CEL expressions used by rules.
+// Compiled programs are cached by expression, so every expression is parsed
+// and type-checked only once.
+type RuleEvaluator struct {
+	env *cel.Env
+
+	mu       sync.Mutex
+	programs map[string]cel.Program
+}
+
+// NewRuleEvaluator creates a RuleEvaluator with object, namespaceObject and gvk bound as variables.
+// The namespace is bound as namespaceObject, because namespace is a reserved identifier in CEL.
+func NewRuleEvaluator() (*RuleEvaluator, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("object", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("namespaceObject", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("gvk", cel.MapType(cel.StringType, cel.StringType)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+
+	return &RuleEvaluator{
+		env:      env,
+		programs: make(map[string]cel.Program),
+	}, nil
+}
+
+// defaultRuleEvaluator is shared by all namespace scopes, so the compiled programs
+// are reused across reconcilers.
+var defaultRuleEvaluator = sync.OnceValues(NewRuleEvaluator)
+
+// Compile returns the program for the given expression, compiling and type-checking it if needed.
+func (e *RuleEvaluator) Compile(expr string) (cel.Program, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if prg, ok := e.programs[expr]; ok {
+		return prg, nil
+	}
+
+	ast, iss := e.env.Compile(expr)
+	if iss.Err() != nil {
+		return nil, fmt.Errorf("failed to compile expression %q: %w", expr, iss.Err())
+	}
+
+	if t := ast.OutputType(); !t.IsExactType(cel.BoolType) && !t.IsExactType(cel.DynType) {
+		return nil, fmt.Errorf("expression %q must evaluate to bool, got %s", expr, t)
+	}
+
+	prg, err := e.env.Program(ast, cel.CostLimit(ruleCostLimit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create program for expression %q: %w", expr, err)
+	}
+
+	if len(e.programs) >= ruleCacheSize {
+		clear(e.programs)
+	}
+	e.programs[expr] = prg
+
+	return prg, nil
+}
+
+// Evaluate runs the expression against the given variables and returns its boolean result.
+func (e *RuleEvaluator) Evaluate(expr string, vars map[string]any) (bool, error) {
+	prg, err := e.Compile(expr)
+	if err != nil {
+		return false, err
+	}
+
+	out, _, err := prg.Eval(vars)
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate expression %q: %w", expr, err)
+	}
+
+	result, ok := out.Value().(bool)
+	if !ok {
+		return false, errors.New("expression did not evaluate to bool")
+	}
+
+	return result, nil
+}
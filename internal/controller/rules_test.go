@@ -0,0 +1,150 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalRules(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		input       string
+		expected    []Rule
+		expectError bool
+	}{
+		"single rule": {
+			input: `- when: object.spec.replicas > 1
+  keys: [pdb.example.com/enabled]`,
+			expected: []Rule{
+				{When: "object.spec.replicas > 1", Keys: []string{"pdb.example.com/enabled"}},
+			},
+		},
+		"empty": {
+			input: "",
+		},
+		"missing when": {
+			input:       `- keys: [foo]`,
+			expectError: true,
+		},
+		"missing keys": {
+			input:       `- when: "true"`,
+			expectError: true,
+		},
+		"unknown field": {
+			input: `- when: "true"
+  keys: [foo]
+  then: bar`,
+			expectError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := unmarshalRules(tc.input)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestRuleEvaluator(t *testing.T) {
+	t.Parallel()
+
+	evaluator, err := NewRuleEvaluator()
+	require.NoError(t, err)
+
+	vars := map[string]any{
+		"object": map[string]any{
+			"metadata": map[string]any{
+				"name":   "test",
+				"labels": map[string]any{"team": "platform"},
+			},
+			"spec": map[string]any{
+				"replicas": int64(3),
+			},
+		},
+		"namespaceObject": map[string]any{
+			"metadata": map[string]any{"name": "test-namespace"},
+		},
+		"gvk": map[string]string{"group": "apps", "version": "v1", "kind": "Deployment"},
+	}
+
+	for name, tc := range map[string]struct {
+		expr         string
+		expected     bool
+		compileError bool
+		evalError    bool
+	}{
+		"comparison": {
+			expr:     "object.spec.replicas > 1",
+			expected: true,
+		},
+		"has label": {
+			expr:     "has(object.metadata.labels.team)",
+			expected: true,
+		},
+		"missing label": {
+			expr:     "has(object.metadata.labels.owner)",
+			expected: false,
+		},
+		"namespace and gvk": {
+			expr:     `namespaceObject.metadata.name == "test-namespace" && gvk.kind == "Deployment"`,
+			expected: true,
+		},
+		"syntax error": {
+			expr:         "object.spec.replicas >",
+			compileError: true,
+		},
+		"non bool result": {
+			expr:         `gvk.kind + "s"`,
+			compileError: true,
+		},
+		"missing field": {
+			expr:      "object.spec.paused",
+			evalError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := evaluator.Compile(tc.expr)
+			if tc.compileError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			result, err := evaluator.Evaluate(tc.expr, vars)
+			if tc.evalError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/anza-labs/scribe/api/v1alpha1"
+	"github.com/anza-labs/scribe/internal/config"
+)
+
+// +kubebuilder:rbac:groups=scribe.anza-labs.dev,resources=scribeconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=scribe.anza-labs.dev,resources=scribeconfigs/status,verbs=get;update;patch
+
+const (
+	// ConditionReady reports whether the ScribeConfig is valid and was applied.
+	ConditionReady = "Ready"
+
+	ReasonApplied     = "Applied"
+	ReasonInvalidSpec = "InvalidSpec"
+	ReasonApplyFailed = "ApplyFailed"
+)
+
+// ScribeConfigReconciler applies the ScribeConfig with the given name to the ControllerSet,
+// and reports the state of the configured types in its status.
+type ScribeConfigReconciler struct {
+	client.Client
+	// Name is the name of the ScribeConfig used by the controller. Other objects are ignored.
+	Name string
+	// Controllers receives the configuration.
+	Controllers *ControllerSet
+
+	events chan event.GenericEvent
+}
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+func (r *ScribeConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx, "name", req.Name)
+
+	sc := &v1alpha1.ScribeConfig{}
+	if err := r.Get(ctx, req.NamespacedName, sc); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.V(0).Info("ScribeConfig not found, stopping all controllers")
+			return ctrl.Result{}, r.Controllers.Update(&config.Config{})
+		}
+
+		return ctrl.Result{}, fmt.Errorf("failed to get the config: %w", err)
+	}
+
+	original := sc.Status.DeepCopy()
+
+	cfg, err := ConfigFromSpec(&sc.Spec)
+	if err != nil {
+		log.V(0).Error(err, "Invalid config")
+		r.setReady(sc, metav1.ConditionFalse, ReasonInvalidSpec, err.Error())
+	} else if err := r.Controllers.Update(cfg); err != nil {
+		log.V(0).Error(err, "Unable to apply config")
+		r.setReady(sc, metav1.ConditionFalse, ReasonApplyFailed, err.Error())
+	} else {
+		r.setReady(sc, metav1.ConditionTrue, ReasonApplied, "Config applied")
+		sc.Status.ObservedGeneration = sc.Generation
+	}
+
+	sc.Status.Types = r.Controllers.TypeStatuses()
+
+	if reflect.DeepEqual(original, &sc.Status) {
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.Status().Update(ctx, sc); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to update status: %w", err)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *ScribeConfigReconciler) setReady(sc *v1alpha1.ScribeConfig, status metav1.ConditionStatus, reason, msg string) {
+	meta.SetStatusCondition(&sc.Status.Conditions, metav1.Condition{
+		Type:               ConditionReady,
+		Status:             status,
+		Reason:             reason,
+		Message:            msg,
+		ObservedGeneration: sc.Generation,
+	})
+}
+
+// SetupWithManager sets up the controller with the Manager. The status is also refreshed
+// whenever the ControllerSet changes, e.g. when a pending type appears.
+func (r *ScribeConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.events = make(chan event.GenericEvent, 1)
+
+	r.Controllers.OnChange(func() {
+		obj := &v1alpha1.ScribeConfig{}
+		obj.SetName(r.Name)
+
+		select {
+		case r.events <- event.GenericEvent{Object: obj}:
+		default:
+		}
+	})
+
+	named := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetName() == r.Name
+	})
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.ScribeConfig{}, builder.WithPredicates(named)).
+		WatchesRawSource(source.Channel(r.events, &handler.EnqueueRequestForObject{})).
+		Complete(r)
+}
+
+// ConfigFromSpec converts the spec to the controller configuration. The spec mirrors the
+// configuration file, so it is encoded and decoded strictly as one, and validated the same way.
+func ConfigFromSpec(spec *v1alpha1.ScribeConfigSpec) (*config.Config, error) {
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal spec: %w", err)
+	}
+
+	// JSON is a subset of YAML.
+	return config.Parse(raw)
+}
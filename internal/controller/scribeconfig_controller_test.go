@@ -0,0 +1,246 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/anza-labs/scribe/api/v1alpha1"
+	"github.com/anza-labs/scribe/internal/config"
+)
+
+func TestConfigFromSpec(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		spec        v1alpha1.ScribeConfigSpec
+		expected    *config.Config
+		expectError bool
+	}{
+		"empty": {
+			expected: &config.Config{},
+		},
+		"types": {
+			spec: v1alpha1.ScribeConfigSpec{
+				Types: []v1alpha1.Type{
+					{APIVersion: "apps/v1", Kind: "Deployment", Fields: v1alpha1.Fields{Deny: []string{}}},
+					{
+						Group: "apps", Kind: "StatefulSet",
+						Filter: v1alpha1.Filter{
+							ObjectSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+						},
+						Controller: v1alpha1.ControllerOptions{
+							MaxConcurrentReconciles: 4,
+							RateLimiter:             v1alpha1.RateLimiter{BaseDelay: &metav1.Duration{Duration: 10 * time.Millisecond}},
+						},
+					},
+				},
+				Limits:            v1alpha1.Limits{ExecutionTimeout: &metav1.Duration{Duration: 2 * time.Second}},
+				DiscoveryInterval: &metav1.Duration{Duration: time.Minute},
+				Filter:            v1alpha1.Filter{ExcludedNamespaces: []string{"kube-system"}},
+			},
+			expected: &config.Config{
+				Types: []config.Type{
+					{APIVersion: "apps/v1", Kind: "Deployment", Fields: config.Fields{Deny: []string{}}},
+					{
+						Group: "apps", Kind: "StatefulSet",
+						Filter: config.Filter{
+							ObjectSelector: &config.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+						},
+						Controller: config.ControllerOptions{
+							MaxConcurrentReconciles: 4,
+							RateLimiter:             config.RateLimiter{BaseDelay: 10 * time.Millisecond},
+						},
+					},
+				},
+				Limits:            config.Limits{ExecutionTimeout: 2 * time.Second},
+				DiscoveryInterval: time.Minute,
+				Filter:            config.Filter{ExcludedNamespaces: []string{"kube-system"}},
+			},
+		},
+		"fractional rate limiter": {
+			spec: v1alpha1.ScribeConfigSpec{
+				Types: []v1alpha1.Type{{
+					APIVersion: "v1", Kind: "Pod",
+					Controller: v1alpha1.ControllerOptions{
+						RateLimiter: v1alpha1.RateLimiter{QPS: 0.5, Burst: 2},
+					},
+				}},
+			},
+			expected: &config.Config{
+				Types: []config.Type{{
+					APIVersion: "v1", Kind: "Pod",
+					Controller: config.ControllerOptions{
+						RateLimiter: config.RateLimiter{QPS: 0.5, Burst: 2},
+					},
+				}},
+			},
+		},
+		"invalid": {
+			spec: v1alpha1.ScribeConfigSpec{
+				Templates: map[string]string{"broken": "{{ .metadata"},
+			},
+			expectError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg, err := ConfigFromSpec(&tc.spec)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, cfg)
+		})
+	}
+}
+
+// TestConfigFromSpecFieldKinds checks that the spec fields decode into configuration fields of the same kind,
+// e.g. that a fractional value accepted by the configuration file is accepted by the CRD too.
+func TestConfigFromSpecFieldKinds(t *testing.T) {
+	t.Parallel()
+
+	var compare func(path string, spec, cfg reflect.Type)
+	compare = func(path string, spec, cfg reflect.Type) {
+		for spec.Kind() == reflect.Pointer || spec.Kind() == reflect.Slice || spec.Kind() == reflect.Map {
+			spec = spec.Elem()
+		}
+		for cfg.Kind() == reflect.Pointer || cfg.Kind() == reflect.Slice || cfg.Kind() == reflect.Map {
+			cfg = cfg.Elem()
+		}
+
+		if spec.Kind() != reflect.Struct || cfg.Kind() != reflect.Struct {
+			if numberKind(spec) != "" && numberKind(cfg) != "" {
+				assert.Equal(t, numberKind(cfg), numberKind(spec), path)
+			}
+			return
+		}
+
+		for i := range spec.NumField() {
+			field := spec.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+
+			for j := range cfg.NumField() {
+				if other, _, _ := strings.Cut(cfg.Field(j).Tag.Get("json"), ","); other == name {
+					compare(path+"."+name, field.Type, cfg.Field(j).Type)
+				}
+			}
+		}
+	}
+
+	compare("spec", reflect.TypeOf(v1alpha1.ScribeConfigSpec{}), reflect.TypeOf(config.Config{}))
+}
+
+// numberKind returns whether the type is an integer or a float, or an empty string otherwise.
+// Durations are integers in the configuration, but strings in the spec, so they are not numbers.
+func numberKind(t reflect.Type) string {
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return ""
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	default:
+		return ""
+	}
+}
+
+func TestScribeConfigReconcile(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+
+	for name, tc := range map[string]struct {
+		spec           v1alpha1.ScribeConfigSpec
+		expectedStatus metav1.ConditionStatus
+		expectedReason string
+	}{
+		"valid": {
+			spec: v1alpha1.ScribeConfigSpec{
+				Types: []v1alpha1.Type{{APIVersion: "apps/v1", Kind: "Deployment"}},
+			},
+			expectedStatus: metav1.ConditionTrue,
+			expectedReason: ReasonApplied,
+		},
+		"invalid": {
+			spec: v1alpha1.ScribeConfigSpec{
+				Types: []v1alpha1.Type{{APIVersion: "apps/v1", Kind: "Deployment"}, {Group: "apps", Kind: "Deployment"}},
+			},
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: ReasonInvalidSpec,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			sc := &v1alpha1.ScribeConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "default", Generation: 2},
+				Spec:       tc.spec,
+			}
+
+			c := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(sc).
+				WithStatusSubresource(sc).
+				Build()
+
+			// The set is not started, so the configuration is only stored.
+			r := &ScribeConfigReconciler{
+				Client:      c,
+				Name:        "default",
+				Controllers: NewControllerSet(nil, &config.Config{}),
+			}
+
+			_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "default"}})
+			require.NoError(t, err)
+
+			result := &v1alpha1.ScribeConfig{}
+			require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: "default"}, result))
+
+			cond := meta.FindStatusCondition(result.Status.Conditions, ConditionReady)
+			require.NotNil(t, cond)
+			assert.Equal(t, tc.expectedStatus, cond.Status)
+			assert.Equal(t, tc.expectedReason, cond.Reason)
+		})
+	}
+}
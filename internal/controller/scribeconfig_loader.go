@@ -0,0 +1,135 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/anza-labs/scribe/api/v1alpha1"
+	"github.com/anza-labs/scribe/internal/config"
+)
+
+// ScribeConfigLoader loads the ScribeConfig with the given name on every replica. Only the leader
+// reconciles the ScribeConfig, while the webhooks are served by all replicas, so they read the
+// configuration from the loader instead. Invalid specs are ignored, and the previous configuration
+// is kept; the reconciler reports them in the status.
+type ScribeConfigLoader struct {
+	// Cache reads and watches the ScribeConfig. The cache of the manager runs on every replica.
+	Cache cache.Cache
+	// Name is the name of the ScribeConfig.
+	Name string
+
+	cfg atomic.Pointer[config.Config]
+}
+
+var _ manager.LeaderElectionRunnable = &ScribeConfigLoader{}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, the configuration is loaded on every replica.
+func (l *ScribeConfigLoader) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable. It loads the configuration once the cache is synced,
+// and again whenever a ScribeConfig changes.
+func (l *ScribeConfigLoader) Start(ctx context.Context) error {
+	informer, err := l.Cache.GetInformer(ctx, &v1alpha1.ScribeConfig{})
+	if err != nil {
+		return fmt.Errorf("unable to watch ScribeConfigs: %w", err)
+	}
+
+	// Changes are coalesced, the configuration is read from the cache anyway.
+	changed := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+
+	if _, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    func(any) { notify() },
+		UpdateFunc: func(any, any) { notify() },
+		DeleteFunc: func(any) { notify() },
+	}); err != nil {
+		return fmt.Errorf("unable to watch ScribeConfigs: %w", err)
+	}
+
+	if !l.Cache.WaitForCacheSync(ctx) {
+		return ctx.Err()
+	}
+
+	for {
+		if err := l.load(ctx); err != nil {
+			log.FromContext(ctx).Error(err, "Unable to load the config", "name", l.Name)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		}
+	}
+}
+
+// load reads the ScribeConfig, and replaces the configuration if its spec is valid. Without
+// a ScribeConfig, the configuration is empty, like the one applied by the reconciler.
+func (l *ScribeConfigLoader) load(ctx context.Context) error {
+	sc := &v1alpha1.ScribeConfig{}
+	if err := l.Cache.Get(ctx, client.ObjectKey{Name: l.Name}, sc); err != nil {
+		if apierrors.IsNotFound(err) {
+			l.cfg.Store(&config.Config{})
+			return nil
+		}
+
+		return fmt.Errorf("failed to get the config: %w", err)
+	}
+
+	cfg, err := ConfigFromSpec(&sc.Spec)
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	l.cfg.Store(cfg)
+
+	return nil
+}
+
+// Config returns the loaded configuration, or nil until a configuration is loaded.
+func (l *ScribeConfigLoader) Config() *config.Config {
+	return l.cfg.Load()
+}
+
+// ReadyzCheck reports whether a configuration is loaded, so the webhooks of the replica are not
+// called before it is.
+func (l *ScribeConfigLoader) ReadyzCheck(_ *http.Request) error {
+	if l.Config() == nil {
+		return errors.New("the config is not loaded yet")
+	}
+
+	return nil
+}
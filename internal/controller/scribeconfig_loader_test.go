@@ -0,0 +1,113 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/anza-labs/scribe/api/v1alpha1"
+	"github.com/anza-labs/scribe/internal/config"
+)
+
+// fakeCache reads objects from a fake client.
+type fakeCache struct {
+	client.Reader
+	*informertest.FakeInformers
+}
+
+func (c fakeCache) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	return c.Reader.Get(ctx, key, obj, opts...)
+}
+
+func (c fakeCache) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return c.Reader.List(ctx, list, opts...)
+}
+
+func TestScribeConfigLoader(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+
+	valid := &v1alpha1.ScribeConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: v1alpha1.ScribeConfigSpec{
+			Types: []v1alpha1.Type{{APIVersion: "v1", Kind: "Pod"}},
+		},
+	}
+	invalid := &v1alpha1.ScribeConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: v1alpha1.ScribeConfigSpec{
+			Templates: map[string]string{"broken": "{{ .metadata"},
+		},
+	}
+
+	for name, tc := range map[string]struct {
+		objects     []client.Object
+		expected    *config.Config
+		expectError bool
+	}{
+		"not found": {
+			expected: &config.Config{},
+		},
+		"valid": {
+			objects:  []client.Object{valid},
+			expected: &config.Config{Types: []config.Type{{APIVersion: "v1", Kind: "Pod"}}},
+		},
+		"invalid": {
+			objects:     []client.Object{invalid},
+			expectError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			l := &ScribeConfigLoader{
+				Cache: fakeCache{
+					Reader:        fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objects...).Build(),
+					FakeInformers: &informertest.FakeInformers{Scheme: scheme},
+				},
+				Name: "default",
+			}
+
+			assert.Nil(t, l.Config())
+			assert.Error(t, l.ReadyzCheck(nil))
+
+			err := l.load(context.Background())
+			if tc.expectError {
+				assert.Error(t, err)
+				assert.Nil(t, l.Config(), "an invalid spec must not be loaded")
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, l.Config())
+			assert.NoError(t, l.ReadyzCheck(nil))
+		})
+	}
+}
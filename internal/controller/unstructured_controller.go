@@ -21,8 +21,12 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
+	"sync"
+	"text/template"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -31,11 +35,18 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/anza-labs/scribe/internal/config"
 )
 
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
@@ -46,6 +57,32 @@ type UnstructuredReconciler struct {
 	gvk      schema.GroupVersionKind
 	Scheme   *runtime.Scheme
 	Recorder record.EventRecorder
+	// Templates contains the shared named templates from the configuration.
+	Templates *template.Template
+	// Limits bounds the resources used by template execution.
+	Limits config.Limits
+	// Fields restricts the object fields that templates can read.
+	Fields config.Fields
+	// Cache keeps parsed templates, and is shared between reconcilers.
+	Cache *TemplateCache
+	// Filter selects the managed objects. Other objects never reach Reconcile.
+	Filter *ObjectFilter
+	// Policy restricts the keys propagated from namespaces.
+	Policy config.Policy
+	// RequireOptIn ignores the namespaces without the opt-in label.
+	RequireOptIn bool
+	// Options tunes the controller created by NewController.
+	Options config.ControllerOptions
+	// Keys are the annotation keys of the instance. The default keys are used if unset.
+	Keys AnnotationKeys
+	// WatchNamespaces restricts the controller to the given namespaces. The namespaces
+	// are polled instead of watched, as watching them requires cluster-wide permissions.
+	WatchNamespaces []string
+	// OptInWarnings reports the namespaces ignored for the missing opt-in label.
+	OptInWarnings *OptInWarnings
+
+	loops   *loopDetector
+	managed sync.Map
 }
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -65,6 +102,7 @@ func (r *UnstructuredReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			// If the resource is not found then it usually means that it was deleted or not created
 			// In this way, we will stop the reconciliation
 			log.V(2).Info("Not found, ignoring since object must be deleted")
+			r.loops.forget(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 
@@ -77,7 +115,20 @@ func (r *UnstructuredReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, nil
 	}
 
-	nss := NewNamespaceScope(r.Client, req.Namespace)
+	opts := []NamespaceScopeOption{
+		WithSharedTemplates(r.Templates),
+		WithLimits(r.Limits),
+		WithFields(r.Fields),
+		WithTemplateCache(r.Cache),
+		WithKeys(r.Keys),
+		WithPolicy(r.Policy),
+		WithNamespaceFilter(r.Filter),
+	}
+	if r.RequireOptIn {
+		opts = append(opts, WithOptIn())
+	}
+
+	nss := NewNamespaceScope(r.Client, req.Namespace, opts...)
 
 	ann, err := nss.UpdateAnnotations(ctx, u.GetAnnotations(), u.Object)
 	if err != nil {
@@ -86,11 +137,24 @@ func (r *UnstructuredReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			return ctrl.Result{}, nil
 		}
 
+		var limitErr *TemplateLimitError
+		if errors.As(err, &limitErr) {
+			// Retrying will not help until the namespace block is changed, which triggers
+			// a new reconciliation anyway, so the error is reported instead of returned.
+			templateLimitErrorsCounter.With(prometheus.Labels{
+				"source_namespace": req.Namespace,
+				"limit":            limitErr.Limit,
+			}).Inc()
+
+			log.V(1).Error(limitErr, "Template limit exceeded")
+			r.Recorder.Event(nss.namespace, corev1.EventTypeWarning, TemplateLimitExceeded, limitErr.Error())
+			return ctrl.Result{}, nil
+		}
+
 		return ctrl.Result{}, fmt.Errorf("failed to update the annotation map: %w", err)
 	}
 
-	ann, validationErrors := ValidateAnnotations(ann)
-	if validationErrors != nil {
+	if validationErrors := nss.ValidationErrors(); validationErrors != nil {
 		validationErrorsCounter.With(prometheus.Labels{"source_namespace": req.Namespace}).Inc()
 
 		log.V(1).Error(validationErrors, "Validation error")
@@ -100,6 +164,31 @@ func (r *UnstructuredReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		}
 	}
 
+	managed := make(map[string]string)
+	for k := range nss.ExpectedAnnotations() {
+		if v, ok := ann[k]; ok {
+			managed[k] = v
+		}
+	}
+
+	inputs, err := loopInputs(nss.namespace, u.Object, managed, r.annotationKeys().LastApplied)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if offending := r.loops.observe(req.NamespacedName, inputs, managed); len(offending) > 0 {
+		// Writing would trigger the watch again, so the object is left unchanged
+		// until the namespace or the object itself changes.
+		updateLoopsCounter.With(prometheus.Labels{"source_namespace": req.Namespace}).Inc()
+
+		msg := fmt.Sprintf("Managed keys change on every reconcile without any change to the inputs, "+
+			"the template is likely non-deterministic: %s", strings.Join(offending, ", "))
+		log.V(0).Info("Update loop detected, skipping", "keys", offending)
+		r.Recorder.Event(u, corev1.EventTypeWarning, UpdateLoopDetected, msg)
+		r.Recorder.Event(nss.namespace, corev1.EventTypeWarning, UpdateLoopDetected, msg)
+		return ctrl.Result{}, nil
+	}
+
 	original := u.DeepCopy()
 	u.SetAnnotations(ann)
 
@@ -115,19 +204,63 @@ func (r *UnstructuredReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	return ctrl.Result{}, nil
 }
 
-// SetupWithManager sets up the controller with the Manager.
-func (r *UnstructuredReconciler) SetupWithManager(mgr ctrl.Manager, gvk schema.GroupVersionKind) error {
+// NewController creates a controller for the given kind. The controller is not managed by
+// the Manager, so it can be started and stopped independently of it.
+func (r *UnstructuredReconciler) NewController(mgr ctrl.Manager, gvk schema.GroupVersionKind) (controller.Controller, error) {
 	u := &unstructured.Unstructured{}
 	u.SetGroupVersionKind(gvk)
 	r.gvk = gvk
+	r.loops = newLoopDetector()
 
-	return ctrl.NewControllerManagedBy(mgr).
-		For(u).
-		Watches(
-			&corev1.Namespace{},
-			handler.EnqueueRequestsFromMapFunc(mapFunc(r)),
-		).
-		Complete(r)
+	opts := controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: r.Options.MaxConcurrentReconciles,
+		// Controllers are recreated with the same name when the configuration changes.
+		SkipNameValidation: ptr.To(true),
+	}
+	if !r.Options.RateLimiter.IsZero() {
+		opts.RateLimiter = newRateLimiter(r.Options.RateLimiter)
+	}
+
+	c, err := controller.NewUnmanaged(strings.ToLower(gvk.GroupKind().String()), mgr, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.Watch(source.Kind[client.Object](
+		mgr.GetCache(), u, &handler.EnqueueRequestForObject{},
+		objectPredicate(mgr.GetClient(), r.Filter),
+	)); err != nil {
+		return nil, err
+	}
+
+	var namespaces source.Source = source.Kind[client.Object](
+		mgr.GetCache(), &corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(mapFunc(r)),
+		namespacePredicate(r.Filter),
+	)
+	if len(r.WatchNamespaces) > 0 {
+		namespaces = pollNamespaces(
+			mgr.GetAPIReader(), r.WatchNamespaces, handler.EnqueueRequestsFromMapFunc(mapFunc(r)),
+			namespacePredicate(r.Filter),
+		)
+	}
+
+	if err := c.Watch(namespaces); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// newRateLimiter creates a rate limiter combining the per-object exponential backoff
+// with the overall bucket, like the controller-runtime default, with the configured values.
+func newRateLimiter(cfg config.RateLimiter) workqueue.TypedRateLimiter[reconcile.Request] {
+	cfg = cfg.WithDefaults()
+
+	return workqueue.NewTypedMaxOfRateLimiter(
+		workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](cfg.BaseDelay, cfg.MaxDelay),
+		&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(cfg.QPS), cfg.Burst)},
+	)
 }
 
 func (r *UnstructuredReconciler) listObjects(ctx context.Context, namespace string) ([]types.NamespacedName, error) {
@@ -136,8 +269,13 @@ func (r *UnstructuredReconciler) listObjects(ctx context.Context, namespace stri
 		"namespace", namespace,
 	)
 
+	if !r.Filter.IncludesNamespaceName(namespace) {
+		log.V(4).Info("Skipping excluded namespace")
+		return nil, nil
+	}
+
 	ul := r.emptyList()
-	if err := r.List(ctx, ul, &client.ListOptions{Namespace: namespace}); err != nil {
+	if err := r.List(ctx, ul, r.Filter.ListOptions(namespace)); err != nil {
 		return nil, fmt.Errorf("unable to list objects: %w", err)
 	}
 
@@ -155,6 +293,93 @@ func (r *UnstructuredReconciler) listObjects(ctx context.Context, namespace stri
 	return nn, nil
 }
 
+func (r *UnstructuredReconciler) annotationKeys() AnnotationKeys {
+	return r.Keys.withDefaults()
+}
+
+// enqueuesNamespace reports whether the objects in the namespace are reconciled after a change of
+// the namespace: while the namespace is managed, and once more after it stops being managed, e.g.
+// because its block or its opt-in label was removed, or it is no longer selected by the filter,
+// so the keys applied from it are removed.
+func (r *UnstructuredReconciler) enqueuesNamespace(ns *corev1.Namespace) bool {
+	if r.managesNamespace(ns) && r.Filter.IncludesNamespace(ns) {
+		r.managed.Store(ns.Name, struct{}{})
+		return true
+	}
+
+	_, wasManaged := r.managed.LoadAndDelete(ns.Name)
+	return wasManaged
+}
+
+// managesNamespace reports whether the namespace has a block, and is opted in if required.
+// Namespaces with a block, but without the required opt-in label, are reported as such.
+func (r *UnstructuredReconciler) managesNamespace(ns *corev1.Namespace) bool {
+	keys := r.annotationKeys()
+
+	if _, ok := ns.Annotations[keys.Annotations]; !ok {
+		r.OptInWarnings.forget(ns)
+		return false
+	}
+
+	if r.RequireOptIn && ns.Labels[keys.Enabled] != "true" {
+		r.OptInWarnings.warn(ns, keys)
+		return false
+	}
+
+	r.OptInWarnings.forget(ns)
+	return true
+}
+
+// OptInWarnings emits the OptInRequired event on namespaces with a block, but without the required
+// opt-in label. It is shared by all reconcilers, so the event is emitted once per block, instead
+// of once per type and per change of the namespace.
+type OptInWarnings struct {
+	recorder record.EventRecorder
+
+	mu     sync.Mutex
+	warned map[string]string
+}
+
+// NewOptInWarnings creates an OptInWarnings emitting the events with the recorder.
+func NewOptInWarnings(recorder record.EventRecorder) *OptInWarnings {
+	return &OptInWarnings{
+		recorder: recorder,
+		warned:   make(map[string]string),
+	}
+}
+
+// warn emits the event, unless it was already emitted for the current block of the namespace.
+func (w *OptInWarnings) warn(ns *corev1.Namespace, keys AnnotationKeys) {
+	if w == nil {
+		return
+	}
+
+	block := ns.Annotations[keys.Annotations]
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if warned, ok := w.warned[ns.Name]; ok && warned == block {
+		return
+	}
+	w.warned[ns.Name] = block
+
+	w.recorder.Eventf(ns, corev1.EventTypeWarning, OptInRequired,
+		"The namespace block is ignored, as the namespace is not labeled with %s=true", keys.Enabled)
+}
+
+// forget allows the event to be emitted again, once the namespace is opted in, or has no block.
+func (w *OptInWarnings) forget(ns *corev1.Namespace) {
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delete(w.warned, ns.Name)
+}
+
 func (r *UnstructuredReconciler) empty(req ctrl.Request) *unstructured.Unstructured {
 	u := &unstructured.Unstructured{}
 
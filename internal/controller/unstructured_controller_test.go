@@ -3,17 +3,24 @@ package controller
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/anza-labs/scribe/internal/config"
 )
 
 func TestListObjects(t *testing.T) {
@@ -25,6 +32,7 @@ func TestListObjects(t *testing.T) {
 
 	for name, tc := range map[string]struct {
 		namespace      string
+		filters        []config.Filter
 		existingPods   []unstructured.Unstructured
 		expectedResult []types.NamespacedName
 		expectedError  error
@@ -55,10 +63,51 @@ func TestListObjects(t *testing.T) {
 				{Namespace: "test-namespace", Name: "pod1"},
 			},
 		},
+		"object selector": {
+			namespace: "test-namespace",
+			filters: []config.Filter{
+				{ObjectSelector: &config.LabelSelector{MatchLabels: map[string]string{"app.kubernetes.io/managed-by": "helm"}}},
+			},
+			existingPods: []unstructured.Unstructured{
+				withLabels(newUnstructuredPod("test-namespace", "pod1"), map[string]string{"app.kubernetes.io/managed-by": "helm"}),
+				newUnstructuredPod("test-namespace", "pod2"),
+			},
+			expectedResult: []types.NamespacedName{
+				{Namespace: "test-namespace", Name: "pod1"},
+			},
+		},
+		"global and type selectors": {
+			namespace: "test-namespace",
+			filters: []config.Filter{
+				{ObjectSelector: &config.LabelSelector{MatchLabels: map[string]string{"app.kubernetes.io/managed-by": "helm"}}},
+				{ObjectSelector: &config.LabelSelector{MatchExpressions: []config.LabelSelectorRequirement{
+					{Key: "tier", Operator: "In", Values: []string{"web"}},
+				}}},
+			},
+			existingPods: []unstructured.Unstructured{
+				withLabels(newUnstructuredPod("test-namespace", "pod1"), map[string]string{"app.kubernetes.io/managed-by": "helm", "tier": "web"}),
+				withLabels(newUnstructuredPod("test-namespace", "pod2"), map[string]string{"app.kubernetes.io/managed-by": "helm"}),
+			},
+			expectedResult: []types.NamespacedName{
+				{Namespace: "test-namespace", Name: "pod1"},
+			},
+		},
+		"excluded namespace": {
+			namespace: "kube-system",
+			filters: []config.Filter{
+				{ExcludedNamespaces: []string{"kube-*"}},
+			},
+			existingPods: []unstructured.Unstructured{
+				newUnstructuredPod("kube-system", "pod1"),
+			},
+		},
 	} {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
+			filter, err := NewObjectFilter(tc.filters...)
+			require.NoError(t, err)
+
 			// Add existing pods to the fake client
 			fakeClient := fake.NewClientBuilder().
 				WithScheme(scheme).
@@ -69,6 +118,7 @@ func TestListObjects(t *testing.T) {
 			reconciler := &UnstructuredReconciler{
 				Client: fakeClient,
 				gvk:    corev1.SchemeGroupVersion.WithKind("Pod"),
+				Filter: filter,
 			}
 
 			// Call the listObjects method
@@ -80,6 +130,60 @@ func TestListObjects(t *testing.T) {
 	}
 }
 
+func TestNewRateLimiter(t *testing.T) {
+	t.Parallel()
+
+	limiter := newRateLimiter(config.RateLimiter{BaseDelay: 10 * time.Millisecond, MaxDelay: 40 * time.Millisecond})
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "pod"}}
+
+	assert.Equal(t, 10*time.Millisecond, limiter.When(req))
+	assert.Equal(t, 20*time.Millisecond, limiter.When(req))
+	assert.Equal(t, 40*time.Millisecond, limiter.When(req))
+	assert.Equal(t, 40*time.Millisecond, limiter.When(req))
+
+	limiter.Forget(req)
+	assert.Equal(t, 10*time.Millisecond, limiter.When(req))
+}
+
+func TestOptInWarnings(t *testing.T) {
+	t.Parallel()
+
+	recorder := record.NewFakeRecorder(10)
+	warnings := NewOptInWarnings(recorder)
+
+	// Reconcilers of different types share the warnings.
+	pods := &UnstructuredReconciler{RequireOptIn: true, OptInWarnings: warnings}
+	deployments := &UnstructuredReconciler{RequireOptIn: true, OptInWarnings: warnings}
+
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "test-namespace",
+			ResourceVersion: "1",
+			Annotations:     map[string]string{annotations: "key=value"},
+		},
+	}
+
+	assert.False(t, pods.managesNamespace(ns))
+	assert.False(t, deployments.managesNamespace(ns))
+
+	// Changes of the namespace, other than its block, are not reported again.
+	ns.ResourceVersion = "2"
+	ns.Labels = map[string]string{"team": "a"}
+	assert.False(t, pods.managesNamespace(ns))
+	assert.Len(t, recorder.Events, 1)
+
+	ns.Annotations[annotations] = "key=other"
+	assert.False(t, pods.managesNamespace(ns))
+	assert.Len(t, recorder.Events, 2)
+
+	// Once opted in, the namespace is reported again when it opts out.
+	ns.Labels[enabled] = "true"
+	assert.True(t, pods.managesNamespace(ns))
+	ns.Labels[enabled] = "false"
+	assert.False(t, pods.managesNamespace(ns))
+	assert.Len(t, recorder.Events, 3)
+}
+
 // Helper function to create an Unstructured object of type Pod
 func newUnstructuredPod(namespace, name string) unstructured.Unstructured {
 	pod := unstructured.Unstructured{}
@@ -90,6 +194,12 @@ func newUnstructuredPod(namespace, name string) unstructured.Unstructured {
 	return pod
 }
 
+// Helper function to set the labels of an Unstructured object
+func withLabels(u unstructured.Unstructured, labels map[string]string) unstructured.Unstructured {
+	u.SetLabels(labels)
+	return u
+}
+
 // Helper function to convert a slice of unstructured.Unstructured to runtime.Object slice
 func convertUnstructuredToObjects(items []unstructured.Unstructured) []client.Object {
 	objects := make([]client.Object, len(items))
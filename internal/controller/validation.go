@@ -20,9 +20,12 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"slices"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/anza-labs/scribe/internal/config"
 )
 
 const AnnotationValidationFailure = "AnnotationValidationFailure"
@@ -97,7 +100,48 @@ func errsFromStrs(strs []string) []error {
 	return errs
 }
 
-func ValidateAnnotations(annotations map[string]string) (map[string]string, *ValidationErrors) {
+// ValidationOption configures additional checks of ValidateAnnotations.
+type ValidationOption func(*validationOptions)
+
+type validationOptions struct {
+	policies   []config.KeyPolicy
+	values     []config.ValueRule
+	restricted *config.Policy
+	literal    []string
+}
+
+// WithKeyPolicies rejects the keys denied, or not allowed, by any of the policies.
+func WithKeyPolicies(policies ...config.KeyPolicy) ValidationOption {
+	return func(o *validationOptions) {
+		o.policies = append(o.policies, policies...)
+	}
+}
+
+// WithValueRules rejects the keys whose values do not satisfy the rules matching them.
+func WithValueRules(rules ...config.ValueRule) ValidationOption {
+	return func(o *validationOptions) {
+		o.values = append(o.values, rules...)
+	}
+}
+
+// WithRestrictedKeys rejects the keys restricted by the policy that are not among the literal keys of
+// the block, e.g. keys built by actions, or injected through values, as the admission webhook cannot
+// authorize them.
+func WithRestrictedKeys(policy config.Policy, literal []string) ValidationOption {
+	return func(o *validationOptions) {
+		o.restricted = &policy
+		o.literal = literal
+	}
+}
+
+// ValidateAnnotations checks the annotations, and returns them without the invalid ones,
+// together with an error for every rejected key.
+func ValidateAnnotations(annotations map[string]string, opts ...ValidationOption) (map[string]string, *ValidationErrors) {
+	o := &validationOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	result := maps.Clone(annotations)
 
 	var validationErrs []*ValidationError
@@ -109,6 +153,26 @@ func ValidateAnnotations(annotations map[string]string) (map[string]string, *Val
 			verr = NewValidationError(verr, k, errsFromStrs(errStrs)...)
 		}
 
+		for _, policy := range o.policies {
+			if err := policy.Check(k); err != nil {
+				verr = NewValidationError(verr, k, err)
+				break
+			}
+		}
+
+		if o.restricted != nil && o.restricted.Restricts(k) && !slices.Contains(o.literal, k) {
+			verr = NewValidationError(verr, k, errors.New("restricted key is not written literally in the block"))
+		}
+
+		for _, rule := range o.values {
+			if !rule.Matches(k) {
+				continue
+			}
+			if err := rule.Check(annotations[k]); err != nil {
+				verr = NewValidationError(verr, k, err)
+			}
+		}
+
 		if verr != nil {
 			delete(result, k)
 			validationErrs = append(validationErrs, verr)
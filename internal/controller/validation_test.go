@@ -21,6 +21,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/anza-labs/scribe/internal/config"
 )
 
 func TestValidationErrors(t *testing.T) {
@@ -75,3 +77,78 @@ func TestValidationError(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateAnnotations(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		annotations      map[string]string
+		opts             []ValidationOption
+		expectedResult   map[string]string
+		expectedRejected []string
+	}{
+		"valid": {
+			annotations:    map[string]string{"team": "a", "example.com/owner": "b"},
+			expectedResult: map[string]string{"team": "a", "example.com/owner": "b"},
+		},
+		"invalid key": {
+			annotations:      map[string]string{"team": "a", "in valid": "b"},
+			expectedResult:   map[string]string{"team": "a"},
+			expectedRejected: []string{"in valid"},
+		},
+		"denied keys": {
+			annotations: map[string]string{"team": "a", "sidecar.istio.io/inject": "true", "kubernetes.io/foo": "c"},
+			opts: []ValidationOption{WithKeyPolicies(
+				config.KeyPolicy{DeniedKeys: []string{"kubernetes.io/*"}},
+				config.KeyPolicy{DeniedKeys: []string{"sidecar.istio.io/inject"}},
+			)},
+			expectedResult:   map[string]string{"team": "a"},
+			expectedRejected: []string{"kubernetes.io/foo", "sidecar.istio.io/inject"},
+		},
+		"allowed keys": {
+			annotations: map[string]string{"team": "a", "owner": "b"},
+			opts: []ValidationOption{WithKeyPolicies(
+				config.KeyPolicy{AllowedKeys: []string{"team"}},
+			)},
+			expectedResult:   map[string]string{"team": "a"},
+			expectedRejected: []string{"owner"},
+		},
+		"invalid values": {
+			annotations: map[string]string{
+				"team":                "platform",
+				"cost-center":         "12345",
+				"owner":               "alice@example.com",
+				"example.com/tier":    "gold",
+				"example.com/comment": "free text",
+			},
+			opts: []ValidationOption{WithValueRules(
+				config.ValueRule{Keys: []string{"team"}, Pattern: "^[a-z-]+$"},
+				config.ValueRule{Keys: []string{"cost-center"}, Pattern: "^[0-9]{6}$"},
+				config.ValueRule{Keys: []string{"owner"}, Pattern: "^[^@]+@[^@]+$", MaxLength: 64},
+				config.ValueRule{Keys: []string{"example.com/*"}, MaxLength: 16},
+				config.ValueRule{Keys: []string{"example.com/tier"}, Enum: []string{"bronze", "silver"}},
+			)},
+			expectedResult: map[string]string{
+				"team":                "platform",
+				"owner":               "alice@example.com",
+				"example.com/comment": "free text",
+			},
+			expectedRejected: []string{"cost-center", "example.com/tier"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, verrs := ValidateAnnotations(tc.annotations, tc.opts...)
+			assert.Equal(t, tc.expectedResult, result)
+
+			var rejected []string
+			if verrs != nil {
+				for _, item := range verrs.Items {
+					rejected = append(rejected, item.Key)
+				}
+			}
+			assert.ElementsMatch(t, tc.expectedRejected, rejected)
+		})
+	}
+}
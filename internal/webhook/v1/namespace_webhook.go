@@ -0,0 +1,224 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/anza-labs/scribe/internal/config"
+	"github.com/anza-labs/scribe/internal/controller"
+)
+
+// nolint:unused
+// log is for logging in this package.
+var namespacelog = logf.Log.WithName("namespace-resource")
+
+// SetupNamespaceWebhookWithManager registers the webhook for Namespace in the manager.
+func SetupNamespaceWebhookWithManager(mgr ctrl.Manager, validator *NamespaceCustomValidator) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&corev1.Namespace{}).
+		WithValidator(validator).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate--v1-namespace,mutating=false,failurePolicy=fail,sideEffects=None,groups="",resources=namespaces,verbs=create;update,versions=v1,name=vnamespace-v1.scribe.anza-labs.dev,admissionReviewVersions=v1
+
+// NamespaceCustomValidator validates the scribe block of Namespaces when they are created or updated.
+// The block is parsed and rendered against a sample object, and the rendered keys are validated
+// against the key policy, so broken blocks are rejected before they are saved. Restricted keys
+// can only be added, or changed, by members of the groups they are restricted to. While keys are
+// restricted, every key must be written literally in the block, so all of them can be authorized;
+// the controllers never apply restricted keys that are not.
+type NamespaceCustomValidator struct {
+	// Keys are the annotation keys of the instance.
+	Keys controller.AnnotationKeys
+	// Config returns the current configuration, or nil until it is loaded.
+	Config func() *config.Config
+}
+
+var _ webhook.CustomValidator = &NamespaceCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type Namespace.
+func (v *NamespaceCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	namespace, ok := obj.(*corev1.Namespace)
+	if !ok {
+		return nil, fmt.Errorf("expected a Namespace object but got %T", obj)
+	}
+	namespacelog.V(2).Info("Validation for Namespace upon creation", "name", namespace.GetName())
+
+	return v.validate(ctx, nil, namespace)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type Namespace.
+// Namespaces whose block, rules and labels did not change are not validated again, so a configuration
+// change never prevents unrelated updates.
+func (v *NamespaceCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldNamespace, ok := oldObj.(*corev1.Namespace)
+	if !ok {
+		return nil, fmt.Errorf("expected a Namespace object for the oldObj but got %T", oldObj)
+	}
+	namespace, ok := newObj.(*corev1.Namespace)
+	if !ok {
+		return nil, fmt.Errorf("expected a Namespace object for the newObj but got %T", newObj)
+	}
+	namespacelog.V(2).Info("Validation for Namespace upon update", "name", namespace.GetName())
+
+	if !v.changed(oldNamespace, namespace) {
+		return nil, nil
+	}
+
+	return v.validate(ctx, oldNamespace, namespace)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type Namespace.
+func (v *NamespaceCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *NamespaceCustomValidator) changed(oldNamespace, namespace *corev1.Namespace) bool {
+	for _, key := range []string{v.Keys.Annotations, v.Keys.Rules} {
+		if oldNamespace.Annotations[key] != namespace.Annotations[key] {
+			return true
+		}
+	}
+
+	// Namespaced key policies select namespaces by their labels.
+	return !maps.Equal(oldNamespace.Labels, namespace.Labels)
+}
+
+func (v *NamespaceCustomValidator) validate(
+	ctx context.Context,
+	oldNamespace, namespace *corev1.Namespace,
+) (admission.Warnings, error) {
+	if _, ok := namespace.Annotations[v.Keys.Annotations]; !ok {
+		return nil, nil
+	}
+
+	// Without a configuration, restricted keys and value rules are unknown, so blocks are denied.
+	cfg := v.Config()
+	if cfg == nil {
+		return nil, v.reject(namespace, field.ErrorList{v.invalid("The configuration is not loaded yet, try again later")})
+	}
+
+	result, err := v.dryRun(ctx, cfg, namespace)
+	if err != nil {
+		var invalid *controller.ValidationErrors
+		if errors.As(err, &invalid) {
+			var allErrs field.ErrorList
+			for _, item := range invalid.Items {
+				allErrs = append(allErrs, v.invalid(item.Message()))
+			}
+			return nil, v.reject(namespace, allErrs)
+		}
+		return nil, v.reject(namespace, field.ErrorList{v.invalid(err.Error())})
+	}
+
+	// Keys that were already in the block, with the same value, were authorized before.
+	previous := &controller.DryRunResult{}
+	if oldNamespace != nil {
+		if old, err := v.dryRun(ctx, cfg, oldNamespace); err == nil {
+			previous = old
+		}
+	}
+
+	var groups []string
+	if req, err := admission.RequestFromContext(ctx); err == nil {
+		groups = req.UserInfo.Groups
+	}
+
+	var allErrs field.ErrorList
+	if result.BuiltKeys && len(cfg.Policy.Restricted) > 0 {
+		allErrs = append(allErrs, v.invalid("Keys built by template actions are not allowed while keys are restricted, "+
+			"every key must be written literally in the block"))
+	}
+
+	for _, key := range result.Keys {
+		value, rendered := result.Annotations[key]
+		oldValue, oldRendered := previous.Annotations[key]
+		if slices.Contains(previous.Keys, key) && rendered == oldRendered && value == oldValue {
+			continue
+		}
+
+		if err := cfg.Policy.Authorize(key, groups); err != nil {
+			allErrs = append(allErrs, v.invalid(fmt.Sprintf("Unauthorized key %q: %s", key, err)))
+		}
+	}
+
+	if len(allErrs) > 0 {
+		return result.Warnings, v.reject(namespace, allErrs)
+	}
+
+	return result.Warnings, nil
+}
+
+// dryRun renders the block of the namespace with the current configuration.
+func (v *NamespaceCustomValidator) dryRun(
+	ctx context.Context,
+	cfg *config.Config,
+	namespace *corev1.Namespace,
+) (*controller.DryRunResult, error) {
+	templates, err := cfg.SharedTemplates()
+	if err != nil {
+		// The configuration is validated when it is loaded, so this is not a problem with the namespace.
+		return nil, fmt.Errorf("unable to load the configuration: %w", err)
+	}
+
+	nss := controller.NewNamespaceScope(nil, namespace.Name,
+		controller.WithSharedTemplates(templates),
+		controller.WithLimits(cfg.Limits),
+		controller.WithKeys(v.Keys),
+		controller.WithPolicy(cfg.Policy),
+	)
+
+	return nss.DryRun(ctx, namespace, sampleObject(namespace.Name))
+}
+
+func (v *NamespaceCustomValidator) invalid(msg string) *field.Error {
+	return field.Invalid(field.NewPath("metadata", "annotations").Key(v.Keys.Annotations), field.OmitValueType{}, msg)
+}
+
+func (v *NamespaceCustomValidator) reject(namespace *corev1.Namespace, allErrs field.ErrorList) error {
+	return apierrors.NewInvalid(corev1.SchemeGroupVersion.WithKind("Namespace").GroupKind(), namespace.Name, allErrs)
+}
+
+// sampleObject returns the object the block is rendered against. Templates reading fields
+// that are not set on it render empty values, or fail and are reported as warnings.
+func sampleObject(namespace string) map[string]any {
+	return map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]any{
+			"name":        "sample",
+			"namespace":   namespace,
+			"labels":      map[string]any{},
+			"annotations": map[string]any{},
+		},
+		"spec": map[string]any{},
+	}
+}
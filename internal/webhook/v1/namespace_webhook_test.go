@@ -0,0 +1,316 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/anza-labs/scribe/internal/config"
+	"github.com/anza-labs/scribe/internal/controller"
+)
+
+const (
+	block = "scribe.anza-labs.dev/annotations"
+	rules = "scribe.anza-labs.dev/rules"
+)
+
+func newValidator(cfg *config.Config) *NamespaceCustomValidator {
+	return &NamespaceCustomValidator{
+		Keys:   controller.DefaultAnnotationKeys,
+		Config: func() *config.Config { return cfg },
+	}
+}
+
+func newNamespace(annotations, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "team",
+			Labels:      labels,
+			Annotations: annotations,
+		},
+	}
+}
+
+func TestNamespaceValidateCreate(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{
+		Templates: map[string]string{
+			"team": `team={{ .metadata.namespace }}`,
+		},
+		Limits: config.Limits{MaxTemplateLength: 64},
+		Policy: config.Policy{
+			KeyPolicy: config.KeyPolicy{DeniedKeys: []string{"sidecar.istio.io/inject"}},
+			Namespaced: []config.NamespacedKeyPolicy{
+				{
+					NamespaceSelector: &config.LabelSelector{MatchLabels: map[string]string{"tenant": "true"}},
+					KeyPolicy:         config.KeyPolicy{AllowedKeys: []string{"team"}},
+				},
+			},
+			Values: []config.ValueRule{
+				{Keys: []string{"email"}, Pattern: "^[^@]+@[^@]+$"},
+			},
+		},
+	}
+
+	for name, tc := range map[string]struct {
+		namespace        *corev1.Namespace
+		expectError      bool
+		expectedWarnings int
+	}{
+		"without block": {
+			namespace: newNamespace(nil, nil),
+		},
+		"valid block": {
+			namespace: newNamespace(map[string]string{block: `{{ template "team" . }},owner=alice`}, nil),
+		},
+		"broken template": {
+			namespace:   newNamespace(map[string]string{block: `team={{ .metadata`}, nil),
+			expectError: true,
+		},
+		"block too long": {
+			namespace:   newNamespace(map[string]string{block: "key=" + strings.Repeat("a", 64)}, nil),
+			expectError: true,
+		},
+		"broken rules": {
+			namespace: newNamespace(map[string]string{
+				block: "team=a",
+				rules: "- when: object.metadata.name ==\n  keys: [team]",
+			}, nil),
+			expectError: true,
+		},
+		"invalid key": {
+			namespace:   newNamespace(map[string]string{block: "in valid=a"}, nil),
+			expectError: true,
+		},
+		"denied key": {
+			namespace:   newNamespace(map[string]string{block: "sidecar.istio.io/inject=true"}, nil),
+			expectError: true,
+		},
+		"key not allowed in namespace": {
+			namespace:   newNamespace(map[string]string{block: "team=a,owner=alice"}, map[string]string{"tenant": "true"}),
+			expectError: true,
+		},
+		"invalid value": {
+			namespace:   newNamespace(map[string]string{block: "email=alice"}, nil),
+			expectError: true,
+		},
+		"value depending on the object": {
+			namespace:        newNamespace(map[string]string{block: "email={{ .metadata.labels.email }}"}, nil),
+			expectedWarnings: 1,
+		},
+		"execution error": {
+			namespace:        newNamespace(map[string]string{block: `image={{ (index .spec.containers 0).image }}`}, nil),
+			expectedWarnings: 1,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			warnings, err := newValidator(cfg).ValidateCreate(context.Background(), tc.namespace)
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Len(t, warnings, tc.expectedWarnings)
+		})
+	}
+}
+
+func TestNamespaceValidateWithoutConfig(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		namespace   *corev1.Namespace
+		expectError bool
+	}{
+		"without block": {
+			namespace: newNamespace(nil, nil),
+		},
+		"with block": {
+			namespace:   newNamespace(map[string]string{block: "team=a"}, nil),
+			expectError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newValidator(nil).ValidateCreate(context.Background(), tc.namespace)
+			if tc.expectError {
+				assert.ErrorContains(t, err, "not loaded")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNamespaceValidateUpdate(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{
+		Policy: config.Policy{
+			KeyPolicy: config.KeyPolicy{DeniedKeys: []string{"owner"}},
+		},
+	}
+
+	denied := map[string]string{block: "owner=alice"}
+
+	for name, tc := range map[string]struct {
+		oldNamespace *corev1.Namespace
+		namespace    *corev1.Namespace
+		expectError  bool
+	}{
+		"unchanged block": {
+			oldNamespace: newNamespace(denied, map[string]string{"team": "a"}),
+			namespace:    newNamespace(denied, map[string]string{"team": "a"}),
+		},
+		"changed block": {
+			oldNamespace: newNamespace(nil, nil),
+			namespace:    newNamespace(denied, nil),
+			expectError:  true,
+		},
+		"changed labels": {
+			oldNamespace: newNamespace(denied, nil),
+			namespace:    newNamespace(denied, map[string]string{"tenant": "true"}),
+			expectError:  true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newValidator(cfg).ValidateUpdate(context.Background(), tc.oldNamespace, tc.namespace)
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNamespaceValidateAuthorization(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{
+		Templates: map[string]string{
+			"mesh": `{{ if .metadata.labels.app }}sidecar.istio.io/inject=true{{ end }}`,
+		},
+		Policy: config.Policy{
+			Restricted: []config.RestrictedKeys{
+				{Keys: []string{"sidecar.istio.io/*"}, Groups: []string{"platform"}},
+			},
+		},
+	}
+
+	inject := map[string]string{block: "team=a,sidecar.istio.io/inject=true"}
+
+	for name, tc := range map[string]struct {
+		oldNamespace *corev1.Namespace
+		namespace    *corev1.Namespace
+		groups       []string
+		expectError  bool
+	}{
+		"unrestricted keys": {
+			namespace: newNamespace(map[string]string{block: "team=a"}, nil),
+		},
+		"restricted key by member": {
+			namespace: newNamespace(inject, nil),
+			groups:    []string{"system:authenticated", "platform"},
+		},
+		"restricted key by other user": {
+			namespace:   newNamespace(inject, nil),
+			groups:      []string{"system:authenticated"},
+			expectError: true,
+		},
+		"restricted key in conditional": {
+			namespace: newNamespace(map[string]string{
+				block: `{{ if .metadata.labels.mesh }}sidecar.istio.io/inject=true{{ end }}`,
+			}, nil),
+			expectError: true,
+		},
+		"restricted key built by action": {
+			namespace: newNamespace(map[string]string{
+				block: `{{ if .metadata.labels.app }}{{ "sidecar.istio.io/inject" }}=false{{ end }}`,
+			}, nil),
+			groups:      []string{"dev"},
+			expectError: true,
+		},
+		"key built by action by member": {
+			namespace: newNamespace(map[string]string{
+				block: `{{ .metadata.namespace }}=a`,
+			}, nil),
+			groups:      []string{"platform"},
+			expectError: true,
+		},
+		"restricted key from shared template": {
+			namespace: newNamespace(map[string]string{
+				block: `{{ template "mesh" . }}`,
+			}, nil),
+			groups:      []string{"dev"},
+			expectError: true,
+		},
+		"value built by action": {
+			namespace: newNamespace(map[string]string{
+				block: `team={{ .metadata.namespace }},sidecar.istio.io/inject=true`,
+			}, nil),
+			groups: []string{"platform"},
+		},
+		"restricted key kept by other user": {
+			oldNamespace: newNamespace(inject, nil),
+			namespace:    newNamespace(map[string]string{block: "team=b,sidecar.istio.io/inject=true"}, nil),
+		},
+		"restricted key changed by other user": {
+			oldNamespace: newNamespace(inject, nil),
+			namespace:    newNamespace(map[string]string{block: "team=a,sidecar.istio.io/inject=false"}, nil),
+			expectError:  true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := admission.NewContextWithRequest(context.Background(), admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UserInfo: authenticationv1.UserInfo{Groups: tc.groups},
+				},
+			})
+
+			var err error
+			if tc.oldNamespace == nil {
+				_, err = newValidator(cfg).ValidateCreate(ctx, tc.namespace)
+			} else {
+				_, err = newValidator(cfg).ValidateUpdate(ctx, tc.oldNamespace, tc.namespace)
+			}
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/anza-labs/scribe/internal/config"
+	"github.com/anza-labs/scribe/internal/controller"
+)
+
+// ObjectWebhookPath is the path the webhook applying annotations to new objects is served at.
+const ObjectWebhookPath = "/mutate-scribe-object"
+
+// nolint:unused
+// log is for logging in this package.
+var objectlog = logf.Log.WithName("object-resource")
+
+// SetupObjectWebhookWithManager registers the webhook applying annotations to new objects in the manager.
+// The managed types are configured at runtime, so the webhook configuration is not generated; see
+// config/webhook/mutating.yaml.
+func SetupObjectWebhookWithManager(mgr ctrl.Manager, mutator *ObjectMutator) error {
+	mgr.GetWebhookServer().Register(ObjectWebhookPath, &webhook.Admission{Handler: mutator})
+	return nil
+}
+
+// ObjectMutator applies the annotations of the namespace block to objects of the configured types
+// when they are created, together with the last-applied bookkeeping, exactly like the controller
+// would, so the annotations are present before anything acts on the object, e.g. sidecar injection.
+// The controller stays responsible for every later change. Problems rendering the block never
+// prevent the creation, they are returned as warnings, and reported by the controller as usual.
+type ObjectMutator struct {
+	// Client reads the namespaces.
+	Client client.Client
+	// Keys are the annotation keys of the instance.
+	Keys controller.AnnotationKeys
+	// Config returns the current configuration, or nil until it is loaded.
+	Config func() *config.Config
+	// RequireOptIn ignores the namespaces without the opt-in label.
+	RequireOptIn bool
+	// WatchNamespaces restricts the webhook to the given namespaces.
+	WatchNamespaces []string
+}
+
+var _ admission.Handler = &ObjectMutator{}
+
+// Handle implements admission.Handler.
+func (m *ObjectMutator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create {
+		return admission.Allowed("")
+	}
+
+	gvk := schema.GroupVersionKind(req.Kind)
+	log := objectlog.WithValues("group_version_kind", gvk, "namespace", req.Namespace)
+
+	// Until the configuration is loaded, the controller applies the annotations later.
+	cfg := m.Config()
+	if cfg == nil {
+		log.V(1).Info("Skipping object, the configuration is not loaded yet")
+		return admission.Allowed("")
+	}
+
+	t, ok := cfg.LookupType(gvk)
+	if !ok {
+		log.V(3).Info("Skipping unmanaged type")
+		return admission.Allowed("")
+	}
+
+	u := &unstructured.Unstructured{}
+	if err := json.Unmarshal(req.Object.Raw, &u.Object); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	// The namespace is not set on objects created with the namespace in the request path only.
+	// Templates see it as set, but only the annotations are patched.
+	object := u.DeepCopy()
+	if object.GetNamespace() == "" {
+		object.SetNamespace(req.Namespace)
+	}
+
+	annotations, err := m.annotate(ctx, cfg, t, object)
+	if err != nil {
+		if errors.Is(err, controller.ErrSkipReconciliation) {
+			log.V(3).Info("Skipping unmanaged object")
+			return admission.Allowed("")
+		}
+
+		log.V(1).Error(err, "Unable to apply annotations")
+		return admission.Allowed("").WithWarnings("scribe: unable to apply annotations: " + err.Error())
+	}
+
+	var warnings []string
+	if validationErrors := annotations.invalid; validationErrors != nil {
+		for _, item := range validationErrors.Items {
+			warnings = append(warnings, "scribe: "+item.Message())
+		}
+	}
+
+	u.SetAnnotations(annotations.result)
+
+	marshaled, err := json.Marshal(u.Object)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled).WithWarnings(warnings...)
+}
+
+// annotations is the result of rendering the namespace block for a new object.
+type annotations struct {
+	result  map[string]string
+	invalid *controller.ValidationErrors
+}
+
+// annotate renders the namespace block for the object, if the object is managed.
+func (m *ObjectMutator) annotate(
+	ctx context.Context,
+	cfg *config.Config,
+	t config.Type,
+	u *unstructured.Unstructured,
+) (*annotations, error) {
+	filters := []config.Filter{cfg.Filter, t.Filter}
+	if len(m.WatchNamespaces) > 0 {
+		filters = append(filters, config.Filter{Namespaces: m.WatchNamespaces})
+	}
+
+	filter, err := controller.NewObjectFilter(filters...)
+	if err != nil {
+		return nil, err
+	}
+
+	if !filter.MatchesLabels(u.GetLabels()) || !filter.IncludesNamespaceName(u.GetNamespace()) {
+		return nil, controller.ErrSkipReconciliation
+	}
+
+	// The namespace is read with the same key as by the namespace scope.
+	ns := &corev1.Namespace{}
+	if err := m.Client.Get(ctx, types.NamespacedName{Namespace: u.GetNamespace(), Name: u.GetNamespace()}, ns); err != nil {
+		return nil, err
+	}
+
+	if !filter.IncludesNamespace(ns) {
+		return nil, controller.ErrSkipReconciliation
+	}
+
+	templates, err := cfg.SharedTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []controller.NamespaceScopeOption{
+		controller.WithSharedTemplates(templates),
+		controller.WithLimits(cfg.Limits),
+		controller.WithFields(t.Fields),
+		controller.WithKeys(m.Keys),
+		controller.WithPolicy(cfg.Policy),
+	}
+	if m.RequireOptIn {
+		opts = append(opts, controller.WithOptIn())
+	}
+
+	nss := controller.NewNamespaceScope(m.Client, u.GetNamespace(), opts...)
+
+	result, err := nss.UpdateAnnotations(ctx, u.GetAnnotations(), u.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	return &annotations{result: result, invalid: nss.ValidationErrors()}, nil
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gomodules.xyz/jsonpatch/v2"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/anza-labs/scribe/internal/config"
+	"github.com/anza-labs/scribe/internal/controller"
+)
+
+func TestObjectMutatorHandle(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{
+		Types: []config.Type{{APIVersion: "v1", Kind: "Pod"}},
+		Policy: config.Policy{
+			KeyPolicy: config.KeyPolicy{DeniedKeys: []string{"owner"}},
+		},
+	}
+
+	pod := metav1.GroupVersionKind{Version: "v1", Kind: "Pod"}
+
+	for name, tc := range map[string]struct {
+		operation        admissionv1.Operation
+		kind             metav1.GroupVersionKind
+		block            string
+		expectedPatches  []string
+		expectedWarnings int
+	}{
+		"applies annotations": {
+			operation:       admissionv1.Create,
+			kind:            pod,
+			block:           "team=a,name={{ .metadata.name }}",
+			expectedPatches: []string{"/metadata/annotations"},
+		},
+		"rejected keys": {
+			operation:        admissionv1.Create,
+			kind:             pod,
+			block:            "team=a,owner=alice",
+			expectedPatches:  []string{"/metadata/annotations"},
+			expectedWarnings: 1,
+		},
+		"broken template": {
+			operation:        admissionv1.Create,
+			kind:             pod,
+			block:            "team={{ .metadata",
+			expectedWarnings: 1,
+		},
+		"update": {
+			operation: admissionv1.Update,
+			kind:      pod,
+			block:     "team=a",
+		},
+		"unmanaged type": {
+			operation: admissionv1.Create,
+			kind:      metav1.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"},
+			block:     "team=a",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ns := newNamespace(map[string]string{block: tc.block}, nil)
+			ns.Namespace = ns.Name
+
+			mutator := &ObjectMutator{
+				Client: fake.NewClientBuilder().WithObjects(ns).Build(),
+				Keys:   controller.DefaultAnnotationKeys,
+				Config: func() *config.Config { return cfg },
+			}
+
+			raw, err := json.Marshal(map[string]any{
+				"apiVersion": "v1",
+				"kind":       tc.kind.Kind,
+				"metadata":   map[string]any{"name": "web"},
+			})
+			require.NoError(t, err)
+
+			resp := mutator.Handle(context.Background(), admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: tc.operation,
+					Kind:      tc.kind,
+					Namespace: ns.Name,
+					Object:    runtime.RawExtension{Raw: raw},
+				},
+			})
+
+			assert.True(t, resp.Allowed)
+			assert.Len(t, resp.Warnings, tc.expectedWarnings)
+
+			paths := make([]string, 0, len(resp.Patches))
+			for _, patch := range resp.Patches {
+				paths = append(paths, patch.Path)
+			}
+			assert.ElementsMatch(t, tc.expectedPatches, paths)
+
+			if len(resp.Patches) > 0 {
+				assertAnnotations(t, resp.Patches, map[string]string{
+					"team": "a",
+				})
+			}
+		})
+	}
+}
+
+// assertAnnotations checks that the patches set the expected annotations, and record them as applied.
+func assertAnnotations(t *testing.T, patches []jsonpatch.JsonPatchOperation, expected map[string]string) {
+	t.Helper()
+
+	annotations, ok := patches[0].Value.(map[string]any)
+	require.True(t, ok, "expected the annotations to be patched, got %v", patches[0].Value)
+
+	for k, v := range expected {
+		assert.Equal(t, v, annotations[k])
+	}
+	assert.Contains(t, annotations, controller.DefaultAnnotationKeys.LastApplied)
+	assert.NotContains(t, annotations, "owner")
+}
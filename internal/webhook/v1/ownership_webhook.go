@@ -0,0 +1,134 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/anza-labs/scribe/internal/controller"
+)
+
+// OwnershipWebhookPath is the path the webhook protecting the managed annotations is served at.
+const OwnershipWebhookPath = "/validate-scribe-object"
+
+// ProtectionMode selects what happens when a managed annotation is changed by someone else than scribe.
+type ProtectionMode string
+
+const (
+	// ProtectionWarn accepts the change, and returns a warning.
+	ProtectionWarn ProtectionMode = "warn"
+	// ProtectionDeny rejects the change.
+	ProtectionDeny ProtectionMode = "deny"
+)
+
+// nolint:unused
+// log is for logging in this package.
+var ownershiplog = logf.Log.WithName("ownership-resource")
+
+// SetupOwnershipWebhookWithManager registers the webhook protecting the managed annotations in the manager.
+// The managed types are configured at runtime, so the webhook configuration is not generated; see
+// config/webhook/ownership.yaml.
+func SetupOwnershipWebhookWithManager(mgr ctrl.Manager, validator *OwnershipValidator) error {
+	switch validator.Mode {
+	case ProtectionWarn, ProtectionDeny:
+	default:
+		return fmt.Errorf("invalid protection mode %q, expected %q or %q", validator.Mode, ProtectionWarn, ProtectionDeny)
+	}
+
+	mgr.GetWebhookServer().Register(OwnershipWebhookPath, &webhook.Admission{Handler: validator})
+	return nil
+}
+
+// OwnershipValidator protects the annotations recorded as applied by scribe in the ownership record
+// of an object, i.e. the last-applied annotation. Changing or removing them, or the record itself,
+// is only expected from scribe: any other change would be reverted by the next reconcile anyway.
+// Depending on the mode, such changes are rejected, or accepted with a warning. The response names
+// the namespace whose block owns the keys, where they have to be changed instead.
+type OwnershipValidator struct {
+	// Keys are the annotation keys of the instance.
+	Keys controller.AnnotationKeys
+	// Username is the name of the user scribe authenticates as, e.g. its service account.
+	Username string
+	// Mode selects whether changes are rejected, or accepted with a warning.
+	Mode ProtectionMode
+}
+
+var _ admission.Handler = &OwnershipValidator{}
+
+// Handle implements admission.Handler.
+func (v *OwnershipValidator) Handle(_ context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Update || req.UserInfo.Username == v.Username {
+		return admission.Allowed("")
+	}
+
+	oldObj, obj := &unstructured.Unstructured{}, &unstructured.Unstructured{}
+	if err := json.Unmarshal(req.OldObject.Raw, &oldObj.Object); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if err := json.Unmarshal(req.Object.Raw, &obj.Object); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	changed := v.changedKeys(oldObj.GetAnnotations(), obj.GetAnnotations())
+	if len(changed) == 0 {
+		return admission.Allowed("")
+	}
+
+	namespace := req.Namespace
+	if namespace == "" {
+		namespace = oldObj.GetNamespace()
+	}
+
+	msg := fmt.Sprintf("annotations %s are managed by scribe from the %s annotation of the namespace %q, "+
+		"change them there instead", strings.Join(changed, ", "), v.Keys.Annotations, namespace)
+
+	ownershiplog.V(2).Info("Managed annotations changed",
+		"namespace", namespace, "name", oldObj.GetName(), "user", req.UserInfo.Username, "keys", changed)
+
+	if v.Mode == ProtectionDeny {
+		return admission.Denied(msg)
+	}
+
+	return admission.Allowed("").WithWarnings(msg)
+}
+
+// changedKeys returns the quoted managed keys changed or removed by the update,
+// including the ownership record itself.
+func (v *OwnershipValidator) changedKeys(oldAnnotations, annotations map[string]string) []string {
+	var changed []string
+
+	for _, key := range append(v.Keys.ManagedKeys(oldAnnotations), v.Keys.LastApplied) {
+		oldValue, existed := oldAnnotations[key]
+		value, exists := annotations[key]
+		if existed != exists || oldValue != value {
+			changed = append(changed, fmt.Sprintf("%q", key))
+		}
+	}
+
+	return changed
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/anza-labs/scribe/internal/controller"
+)
+
+const scribeUser = "system:serviceaccount:scribe-system:scribe-controller-manager"
+
+func rawObject(t *testing.T, annotations map[string]string) runtime.RawExtension {
+	t.Helper()
+
+	raw, err := json.Marshal(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]any{
+			"name":        "web",
+			"namespace":   "team",
+			"annotations": annotations,
+		},
+	})
+	require.NoError(t, err)
+
+	return runtime.RawExtension{Raw: raw}
+}
+
+func TestOwnershipValidatorHandle(t *testing.T) {
+	t.Parallel()
+
+	lastApplied := controller.DefaultAnnotationKeys.LastApplied
+	managed := map[string]string{
+		lastApplied: "team=a",
+		"team":      "a",
+		"owner":     "alice",
+	}
+
+	for name, tc := range map[string]struct {
+		mode             ProtectionMode
+		username         string
+		annotations      map[string]string
+		expectAllowed    bool
+		expectedWarnings int
+	}{
+		"unmanaged key changed": {
+			mode:          ProtectionDeny,
+			annotations:   map[string]string{lastApplied: "team=a", "team": "a", "owner": "bob"},
+			expectAllowed: true,
+		},
+		"managed key changed": {
+			mode:        ProtectionDeny,
+			annotations: map[string]string{lastApplied: "team=a", "team": "b", "owner": "alice"},
+		},
+		"managed key removed": {
+			mode:        ProtectionDeny,
+			annotations: map[string]string{lastApplied: "team=a", "owner": "alice"},
+		},
+		"ownership record removed": {
+			mode:        ProtectionDeny,
+			annotations: map[string]string{"team": "a", "owner": "alice"},
+		},
+		"managed key changed by scribe": {
+			mode:          ProtectionDeny,
+			username:      scribeUser,
+			annotations:   map[string]string{lastApplied: "team=b", "team": "b", "owner": "alice"},
+			expectAllowed: true,
+		},
+		"managed key changed with warning": {
+			mode:             ProtectionWarn,
+			annotations:      map[string]string{lastApplied: "team=a", "team": "b", "owner": "alice"},
+			expectAllowed:    true,
+			expectedWarnings: 1,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			validator := &OwnershipValidator{
+				Keys:     controller.DefaultAnnotationKeys,
+				Username: scribeUser,
+				Mode:     tc.mode,
+			}
+
+			resp := validator.Handle(context.Background(), admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Update,
+					Namespace: "team",
+					UserInfo:  authenticationv1.UserInfo{Username: tc.username},
+					OldObject: rawObject(t, managed),
+					Object:    rawObject(t, tc.annotations),
+				},
+			})
+
+			assert.Equal(t, tc.expectAllowed, resp.Allowed)
+			assert.Len(t, resp.Warnings, tc.expectedWarnings)
+			if !resp.Allowed {
+				assert.Contains(t, resp.Result.Message, `namespace "team"`)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	scribev1alpha1 "github.com/anza-labs/scribe/api/v1alpha1"
+	"github.com/anza-labs/scribe/internal/config"
+	"github.com/anza-labs/scribe/internal/controller"
+)
+
+// nolint:unused
+// log is for logging in this package.
+var scribeconfiglog = logf.Log.WithName("scribeconfig-resource")
+
+// SetupScribeConfigWebhookWithManager registers the webhook for ScribeConfig in the manager.
+func SetupScribeConfigWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&scribev1alpha1.ScribeConfig{}).
+		WithValidator(&ScribeConfigCustomValidator{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-scribe-anza-labs-dev-v1alpha1-scribeconfig,mutating=false,failurePolicy=fail,sideEffects=None,groups=scribe.anza-labs.dev,resources=scribeconfigs,verbs=create;update,versions=v1alpha1,name=vscribeconfig-v1alpha1.scribe.anza-labs.dev,admissionReviewVersions=v1
+
+// ScribeConfigCustomValidator validates the ScribeConfig spec the same way as the configuration file,
+// so an invalid configuration is rejected instead of being reported in the status.
+type ScribeConfigCustomValidator struct{}
+
+var _ webhook.CustomValidator = &ScribeConfigCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type ScribeConfig.
+func (v *ScribeConfigCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	scribeconfig, ok := obj.(*scribev1alpha1.ScribeConfig)
+	if !ok {
+		return nil, fmt.Errorf("expected a ScribeConfig object but got %T", obj)
+	}
+	scribeconfiglog.V(2).Info("Validation for ScribeConfig upon creation", "name", scribeconfig.GetName())
+
+	return nil, validateScribeConfig(scribeconfig)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type ScribeConfig.
+func (v *ScribeConfigCustomValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	scribeconfig, ok := newObj.(*scribev1alpha1.ScribeConfig)
+	if !ok {
+		return nil, fmt.Errorf("expected a ScribeConfig object for the newObj but got %T", newObj)
+	}
+	scribeconfiglog.V(2).Info("Validation for ScribeConfig upon update", "name", scribeconfig.GetName())
+
+	return nil, validateScribeConfig(scribeconfig)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type ScribeConfig.
+func (v *ScribeConfigCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateScribeConfig(scribeconfig *scribev1alpha1.ScribeConfig) error {
+	_, err := controller.ConfigFromSpec(&scribeconfig.Spec)
+	if err == nil {
+		return nil
+	}
+
+	// The field errors are wrapped with the stage that failed, decoding or validating.
+	if inner := errors.Unwrap(err); inner != nil {
+		err = inner
+	}
+
+	spec := field.NewPath("spec")
+
+	var allErrs field.ErrorList
+	for _, fe := range config.FieldErrors(err) {
+		path := spec
+		if fe.Path != "" {
+			path = spec.Child(fe.Path)
+		}
+		allErrs = append(allErrs, field.Invalid(path, field.OmitValueType{}, fe.Err.Error()))
+	}
+
+	return apierrors.NewInvalid(scribev1alpha1.GroupVersion.WithKind("ScribeConfig").GroupKind(), scribeconfig.Name, allErrs)
+}
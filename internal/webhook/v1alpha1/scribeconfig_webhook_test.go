@@ -0,0 +1,83 @@
+/*
+Copyright 2025 anza-labs contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	scribev1alpha1 "github.com/anza-labs/scribe/api/v1alpha1"
+)
+
+func TestScribeConfigValidateCreate(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		spec           scribev1alpha1.ScribeConfigSpec
+		expectedFields []string
+	}{
+		"valid": {
+			spec: scribev1alpha1.ScribeConfigSpec{
+				Types: []scribev1alpha1.Type{{APIVersion: "apps/v1", Kind: "Deployment"}},
+			},
+		},
+		"invalid": {
+			spec: scribev1alpha1.ScribeConfigSpec{
+				Types: []scribev1alpha1.Type{
+					{APIVersion: "apps/v1", Kind: "Deployment"},
+					{Group: "apps", Kind: "Deployment"},
+				},
+				Templates: map[string]string{"broken": "{{ .metadata"},
+				Policy: scribev1alpha1.Policy{
+					KeyPolicy: scribev1alpha1.KeyPolicy{DeniedKeys: []string{"[team"}},
+				},
+			},
+			expectedFields: []string{"spec.policy.deniedKeys[0]", "spec.templates.broken", "spec.types[1]"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			sc := &scribev1alpha1.ScribeConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "default"},
+				Spec:       tc.spec,
+			}
+
+			_, err := (&ScribeConfigCustomValidator{}).ValidateCreate(context.Background(), sc)
+			if len(tc.expectedFields) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			var statusErr *apierrors.StatusError
+			require.True(t, errors.As(err, &statusErr) && apierrors.IsInvalid(err), "expected an invalid error, got %v", err)
+
+			var fields []string
+			for _, cause := range statusErr.ErrStatus.Details.Causes {
+				fields = append(fields, cause.Field)
+			}
+			assert.ElementsMatch(t, tc.expectedFields, fields)
+		})
+	}
+}